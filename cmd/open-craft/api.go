@@ -0,0 +1,462 @@
+package main
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+
+	"github.com/tracepanic/open-craft/craft"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// CombineRequest names the elements to combine, either as ElementOne and
+// ElementTwo or, for recipes with more inputs, as Elements.
+type CombineRequest struct {
+	ElementOne string   `json:"element_one,omitempty"`
+	ElementTwo string   `json:"element_two,omitempty"`
+	Elements   []string `json:"elements,omitempty"`
+}
+
+func (r CombineRequest) inputs() []string {
+	var inputs []string
+	for _, name := range append([]string{r.ElementOne, r.ElementTwo}, r.Elements...) {
+		if name = craft.NormalizeElementName(name); name != "" {
+			inputs = append(inputs, name)
+		}
+	}
+	return inputs
+}
+
+// CombineResponse reports the names of the elements produced. Result holds
+// the first of Results for clients that predate multi-result recipes.
+// Locked lists what the player still has to do before the recipe works.
+type CombineResponse struct {
+	Success      bool                  `json:"success"`
+	Result       string                `json:"result,omitempty"`
+	Results      []string              `json:"results,omitempty"`
+	New          bool                  `json:"new,omitempty"`
+	Locked       []string              `json:"locked,omitempty"`
+	Achievements []AchievementResponse `json:"achievements,omitempty"`
+	Error        string                `json:"error,omitempty"`
+}
+
+func newCombineResponse(content *craft.Content, result craft.CombineResult) CombineResponse {
+	if len(result.Locked) > 0 {
+		return CombineResponse{Success: false, Locked: result.Locked, Error: "These elements cannot be combined yet"}
+	}
+	if !result.Success() {
+		return CombineResponse{Success: false, Error: "These elements cannot be combined"}
+	}
+
+	response := CombineResponse{Success: true, New: len(result.New) > 0}
+	for _, element := range result.Results {
+		response.Results = append(response.Results, content.Elements[element].Name)
+	}
+	response.Result = response.Results[0]
+	for _, id := range result.Achievements {
+		response.Achievements = append(response.Achievements, newAchievementResponse(content, id))
+	}
+	return response
+}
+
+type AchievementResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func newAchievementResponse(content *craft.Content, id string) AchievementResponse {
+	achievement, _ := content.Achievement(id)
+	return AchievementResponse{
+		ID:          id,
+		Name:        achievement.Name,
+		Description: achievement.Description,
+	}
+}
+
+type ElementResponse struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// PlayerResponse describes a player. Restored explains, once, that their
+// damaged save was restored from a backup.
+type PlayerResponse struct {
+	ID             string                `json:"id"`
+	Discovered     []ElementResponse     `json:"discovered"`
+	TotalElements  int                   `json:"total_elements"`
+	Achievements   []AchievementResponse `json:"achievements"`
+	Attempts       int                   `json:"attempts"`
+	FailedAttempts int                   `json:"failed_attempts"`
+	Restored       string                `json:"restored,omitempty"`
+}
+
+// TriedResponse is a combination a player has tried. Inputs and Results are
+// element keys.
+type TriedResponse struct {
+	Inputs  []string `json:"inputs"`
+	Count   int      `json:"count"`
+	Results []string `json:"results"`
+}
+
+type CategoryResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Emoji    string `json:"emoji"`
+	Order    int    `json:"order"`
+	Elements int    `json:"elements"`
+}
+
+type StepResponse struct {
+	Inputs []string `json:"inputs"`
+	Result string   `json:"result"`
+	Random bool     `json:"random,omitempty"`
+}
+
+type PathResponse struct {
+	Target string         `json:"target"`
+	Steps  []StepResponse `json:"steps"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+var playerIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+func apiPlayer(id string) string {
+	return "api/" + id
+}
+
+func newPlayerID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
+
+func newPlayerResponse(id string, gameState *craft.GameState) PlayerResponse {
+	response := PlayerResponse{
+		ID:             id,
+		Discovered:     make([]ElementResponse, 0, len(gameState.Discovered)),
+		TotalElements:  len(gameState.Elements),
+		Achievements:   make([]AchievementResponse, 0, len(gameState.Unlocked)),
+		Attempts:       gameState.Attempts,
+		FailedAttempts: gameState.FailedAttempts,
+	}
+
+	if gameState.Restored != nil {
+		// The wrapped error names files on the server.
+		response.Restored = craft.ErrRestored.Error()
+		gameState.Restored = nil
+	}
+
+	for _, name := range gameState.SortedDiscovered() {
+		element := gameState.Elements[name]
+		response.Discovered = append(response.Discovered, ElementResponse{
+			Key:      name,
+			Name:     element.Name,
+			Category: element.Category,
+		})
+	}
+
+	for _, achievement := range gameState.Achievements {
+		if _, unlocked := gameState.Unlocked[achievement.ID]; unlocked {
+			response.Achievements = append(response.Achievements, newAchievementResponse(gameState.Content, achievement.ID))
+		}
+	}
+
+	return response
+}
+
+type apiServer struct {
+	content  *craft.Content
+	sessions *craft.Sessions
+	races    *craft.Races
+}
+
+func newAPIHandler(content *craft.Content, sessions *craft.Sessions, races *craft.Races) http.Handler {
+	s := &apiServer{content: content, sessions: sessions, races: races}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.json", handleOpenAPI)
+	mux.HandleFunc("/combine", handleCombineAPI(content))
+	mux.HandleFunc("GET /categories", s.handleCategories)
+	mux.HandleFunc("GET /path", s.handlePath)
+	mux.HandleFunc("POST /players", s.handleCreatePlayer)
+	mux.HandleFunc("GET /players/{id}", s.withPlayer(s.handleGetPlayer))
+	mux.HandleFunc("POST /players/{id}/combine", s.withPlayer(s.handleCombine))
+	mux.HandleFunc("GET /players/{id}/tried", s.withPlayer(s.handleTried))
+	mux.HandleFunc("DELETE /players/{id}/progress", s.withPlayer(s.handleReset))
+	mux.HandleFunc("GET /players/{id}/save", s.withPlayer(s.handleExport))
+	mux.HandleFunc("PUT /players/{id}/save", s.withPlayer(s.handleImport))
+	mux.HandleFunc("POST /races", s.handleCreateRace)
+	mux.HandleFunc("GET /races/{race}", s.withRace(s.handleGetRace))
+	mux.HandleFunc("POST /races/{race}/racers", s.withRace(s.handleJoinRace))
+	mux.HandleFunc("POST /races/{race}/start", s.withRace(s.handleStartRace))
+	mux.HandleFunc("POST /races/{race}/combine", s.withRace(s.handleRaceCombine))
+	return mux
+}
+
+// withPlayer resolves the {id} path value to the locked game state of a
+// player created with POST /players.
+func (s *apiServer) withPlayer(handler func(http.ResponseWriter, *http.Request, string, *craft.GameState)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if !playerIDPattern.MatchString(id) {
+			writeError(w, http.StatusBadRequest, "Invalid player ID")
+			return
+		}
+
+		gameState, err := s.sessions.Find(apiPlayer(id))
+		if errors.Is(err, craft.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "Player not found")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error loading game state")
+			return
+		}
+
+		gameState.Lock()
+		defer gameState.Unlock()
+
+		handler(w, r, id, gameState)
+	}
+}
+
+func (s *apiServer) handleCategories(w http.ResponseWriter, r *http.Request) {
+	counts := make(map[string]int)
+	for _, element := range s.content.Elements {
+		counts[element.Category]++
+	}
+
+	categories := make([]CategoryResponse, 0, len(s.content.Categories))
+	for _, category := range s.content.Categories {
+		categories = append(categories, CategoryResponse{
+			ID:       category.ID,
+			Name:     category.Name,
+			Emoji:    category.Emoji,
+			Order:    category.Order,
+			Elements: counts[category.ID],
+		})
+	}
+
+	writeJSON(w, http.StatusOK, categories)
+}
+
+// handlePath finds the shortest way to craft the target element, starting
+// from the elements of the given player or from the starting elements.
+func (s *apiServer) handlePath(w http.ResponseWriter, r *http.Request) {
+	target := craft.NormalizeElementName(r.URL.Query().Get("target"))
+	have := craft.StartingElements
+
+	if id := r.URL.Query().Get("player"); id != "" {
+		if !playerIDPattern.MatchString(id) {
+			writeError(w, http.StatusBadRequest, "Invalid player ID")
+			return
+		}
+
+		gameState, err := s.sessions.Find(apiPlayer(id))
+		if errors.Is(err, craft.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "Player not found")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error loading game state")
+			return
+		}
+
+		gameState.Lock()
+		have = slices.Clone(gameState.Discovered)
+		gameState.Unlock()
+	}
+
+	steps, err := s.content.ShortestPath(have, target)
+	if errors.Is(err, craft.ErrUnreachable) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response := PathResponse{Target: target, Steps: make([]StepResponse, 0, len(steps))}
+	for _, step := range steps {
+		response.Steps = append(response.Steps, StepResponse{
+			Inputs: step.Inputs,
+			Result: step.Result,
+			Random: step.Random,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *apiServer) handleCreatePlayer(w http.ResponseWriter, r *http.Request) {
+	id := newPlayerID()
+
+	gameState, err := s.sessions.Get(apiPlayer(id))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error creating player")
+		return
+	}
+
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	writeJSON(w, http.StatusCreated, newPlayerResponse(id, gameState))
+}
+
+func (s *apiServer) handleGetPlayer(w http.ResponseWriter, r *http.Request, id string, gameState *craft.GameState) {
+	writeJSON(w, http.StatusOK, newPlayerResponse(id, gameState))
+}
+
+func (s *apiServer) handleCombine(w http.ResponseWriter, r *http.Request, id string, gameState *craft.GameState) {
+	var request CombineRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	inputs := request.inputs()
+	if len(inputs) < 2 {
+		writeJSON(w, http.StatusBadRequest, CombineResponse{
+			Success: false,
+			Error:   "At least two elements are needed",
+		})
+		return
+	}
+
+	for _, input := range inputs {
+		if !gameState.IsDiscovered(input) {
+			writeJSON(w, http.StatusBadRequest, CombineResponse{
+				Success: false,
+				Error:   "You haven't discovered one or more of these elements yet",
+			})
+			return
+		}
+	}
+
+	response := newCombineResponse(gameState.Content, gameState.Combine(inputs...))
+
+	if err := gameState.Save(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error saving progress")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *apiServer) handleTried(w http.ResponseWriter, r *http.Request, id string, gameState *craft.GameState) {
+	keys := slices.Sorted(maps.Keys(gameState.Tried))
+
+	tried := make([]TriedResponse, 0, len(keys))
+	for _, key := range keys {
+		attempt := gameState.Tried[key]
+		tried = append(tried, TriedResponse{
+			Inputs:  craft.SplitRecipeKey(key),
+			Count:   attempt.Count,
+			Results: append([]string{}, attempt.Results...),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, tried)
+}
+
+func (s *apiServer) handleReset(w http.ResponseWriter, r *http.Request, id string, gameState *craft.GameState) {
+	if err := gameState.Reset(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error resetting progress")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPlayerResponse(id, gameState))
+}
+
+func (s *apiServer) handleExport(w http.ResponseWriter, r *http.Request, id string, gameState *craft.GameState) {
+	data, err := craft.EncodeProgress(gameState.Progress)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error exporting progress")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="progress.json"`)
+	w.Write(data)
+}
+
+func (s *apiServer) handleImport(w http.ResponseWriter, r *http.Request, id string, gameState *craft.GameState) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Error reading save file")
+		return
+	}
+
+	progress, err := craft.DecodeProgress(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid save file: "+err.Error())
+		return
+	}
+
+	if err := gameState.ValidateProgress(progress); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid save file: "+err.Error())
+		return
+	}
+
+	if err := gameState.Replace(progress); err != nil {
+		writeError(w, http.StatusInternalServerError, "Error saving progress")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPlayerResponse(id, gameState))
+}
+
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
+
+func handleCombineAPI(content *craft.Content) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		elem1 := craft.NormalizeElementName(r.URL.Query().Get("element-one"))
+		elem2 := craft.NormalizeElementName(r.URL.Query().Get("element-two"))
+
+		// Without a player nothing has been discovered, so recipes with
+		// requirements stay locked.
+		var result craft.CombineResult
+		if recipe, exists := content.LookupRecipe(elem1, elem2); exists {
+			if result.Locked = content.Unmet(recipe.Requires, nil); len(result.Locked) == 0 {
+				result.Results = recipe.Roll(nil)
+			}
+		}
+
+		json.NewEncoder(w).Encode(newCombineResponse(content, result))
+	}
+}
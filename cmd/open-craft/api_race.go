@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/tracepanic/open-craft/craft"
+)
+
+// RaceRequest creates a race. An empty target is picked from the seed, and
+// a zero seed is picked at random.
+type RaceRequest struct {
+	Target           string `json:"target,omitempty"`
+	Seed             uint64 `json:"seed,omitempty"`
+	TimeLimitSeconds int    `json:"time_limit_seconds,omitempty"`
+}
+
+// JoinRaceRequest adds an existing player to a race lobby under Name, as
+// Race.Join does.
+type JoinRaceRequest struct {
+	Player string `json:"player"`
+	Name   string `json:"name,omitempty"`
+}
+
+// JoinRaceResponse hands the racer the secret token they combine with. The
+// player ID is never shown in a race, since it gives access to their game.
+type JoinRaceResponse struct {
+	Token string       `json:"token"`
+	Index int          `json:"index"`
+	Race  RaceResponse `json:"race"`
+}
+
+// RaceCombineRequest names the elements to combine like CombineRequest, in
+// the race game of the racer Token was given to.
+type RaceCombineRequest struct {
+	Token      string   `json:"token"`
+	ElementOne string   `json:"element_one,omitempty"`
+	ElementTwo string   `json:"element_two,omitempty"`
+	Elements   []string `json:"elements,omitempty"`
+}
+
+// RacerResponse is a racer's standing under their public Index. TimeSeconds
+// is how long they took to craft the target, if they did.
+type RacerResponse struct {
+	Index       int     `json:"index"`
+	Name        string  `json:"name"`
+	Discovered  int     `json:"discovered"`
+	Attempts    int     `json:"attempts"`
+	Finished    bool    `json:"finished"`
+	TimeSeconds float64 `json:"time_seconds,omitempty"`
+}
+
+// RaceResponse describes a race. Racers are ordered by standing, the winner
+// first, and Winner is the index of the winner.
+type RaceResponse struct {
+	ID               string          `json:"id"`
+	Target           ElementResponse `json:"target"`
+	Seed             uint64          `json:"seed"`
+	State            string          `json:"state"`
+	TimeLimitSeconds int             `json:"time_limit_seconds"`
+	ElapsedSeconds   float64         `json:"elapsed_seconds"`
+	Winner           *int            `json:"winner,omitempty"`
+	Racers           []RacerResponse `json:"racers"`
+}
+
+type RaceCombineResponse struct {
+	Result CombineResponse `json:"result"`
+	Race   RaceResponse    `json:"race"`
+}
+
+func newRaceResponse(content *craft.Content, status craft.RaceStatus) RaceResponse {
+	target := content.Elements[status.Target]
+	response := RaceResponse{
+		ID:               status.ID,
+		Target:           ElementResponse{Key: status.Target, Name: target.Name, Category: target.Category},
+		Seed:             status.Seed,
+		State:            string(status.State),
+		TimeLimitSeconds: int(status.Limit.Seconds()),
+		ElapsedSeconds:   status.Elapsed.Seconds(),
+		Racers:           make([]RacerResponse, 0, len(status.Standings)),
+	}
+
+	for _, standing := range status.Standings {
+		if standing.Racer == status.Winner {
+			response.Winner = &standing.Index
+		}
+		response.Racers = append(response.Racers, RacerResponse{
+			Index:       standing.Index,
+			Name:        standing.Name,
+			Discovered:  standing.Discovered,
+			Attempts:    standing.Attempts,
+			Finished:    standing.Finished,
+			TimeSeconds: standing.Time.Seconds(),
+		})
+	}
+	return response
+}
+
+func writeRaceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, craft.ErrRaceNotFound):
+		writeError(w, http.StatusNotFound, "Race not found")
+	case errors.Is(err, craft.ErrRaceStarted), errors.Is(err, craft.ErrRaceNotRunning):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, craft.ErrNotRacing):
+		writeError(w, http.StatusForbidden, "You haven't joined this race")
+	default:
+		writeError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// withRace resolves the {race} path value.
+func (s *apiServer) withRace(handler func(http.ResponseWriter, *http.Request, *craft.Race)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		race, err := s.races.Get(r.PathValue("race"))
+		if err != nil {
+			writeRaceError(w, err)
+			return
+		}
+
+		handler(w, r, race)
+	}
+}
+
+func (s *apiServer) handleCreateRace(w http.ResponseWriter, r *http.Request) {
+	var request RaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if request.TimeLimitSeconds < 0 {
+		writeError(w, http.StatusBadRequest, "Invalid time limit")
+		return
+	}
+
+	limit := craft.DefaultRaceLimit
+	if request.TimeLimitSeconds > 0 {
+		limit = time.Duration(request.TimeLimitSeconds) * time.Second
+	}
+
+	race, err := s.races.Create(craft.NormalizeElementName(request.Target), request.Seed, limit)
+	if err != nil {
+		writeRaceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newRaceResponse(s.content, race.Status(time.Now())))
+}
+
+func (s *apiServer) handleGetRace(w http.ResponseWriter, r *http.Request, race *craft.Race) {
+	writeJSON(w, http.StatusOK, newRaceResponse(s.content, race.Status(time.Now())))
+}
+
+func (s *apiServer) handleJoinRace(w http.ResponseWriter, r *http.Request, race *craft.Race) {
+	var request JoinRaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if !playerIDPattern.MatchString(request.Player) {
+		writeError(w, http.StatusBadRequest, "Invalid player ID")
+		return
+	}
+
+	if _, err := s.sessions.Find(apiPlayer(request.Player)); errors.Is(err, craft.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "Player not found")
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error loading game state")
+		return
+	}
+
+	_, token, err := s.races.Join(race.ID, apiPlayer(request.Player), request.Name)
+	if err != nil {
+		writeRaceError(w, err)
+		return
+	}
+
+	status := race.Status(time.Now())
+	response := JoinRaceResponse{Token: token, Race: newRaceResponse(s.content, status)}
+	for _, standing := range status.Standings {
+		if standing.Racer == apiPlayer(request.Player) {
+			response.Index = standing.Index
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *apiServer) handleStartRace(w http.ResponseWriter, r *http.Request, race *craft.Race) {
+	now := time.Now()
+	if err := race.Start(now); err != nil {
+		writeRaceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newRaceResponse(s.content, race.Status(now)))
+}
+
+func (s *apiServer) handleRaceCombine(w http.ResponseWriter, r *http.Request, race *craft.Race) {
+	var request RaceCombineRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	racer, err := race.ByToken(request.Token)
+	if err != nil {
+		writeRaceError(w, err)
+		return
+	}
+
+	inputs := CombineRequest{
+		ElementOne: request.ElementOne,
+		ElementTwo: request.ElementTwo,
+		Elements:   request.Elements,
+	}.inputs()
+	if len(inputs) < 2 {
+		writeError(w, http.StatusBadRequest, "At least two elements are needed")
+		return
+	}
+
+	now := time.Now()
+	result, err := race.Combine(racer, now, inputs...)
+	if err != nil {
+		writeRaceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, RaceCombineResponse{
+		Result: newCombineResponse(s.content, result),
+		Race:   newRaceResponse(s.content, race.Status(now)),
+	})
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/tracepanic/open-craft/craft"
+	"github.com/tracepanic/open-craft/data"
+)
+
+func newTestAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	content, err := craft.LoadContent(data.FS)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+
+	sessions := craft.NewSessions(content, craft.NewMemoryStore(), craft.SourceAPI)
+	server := httptest.NewServer(newAPIHandler(content, sessions, craft.NewRaces(content)))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func doJSON(t *testing.T, method, url string, body any, wantStatus int, v any) {
+	t.Helper()
+
+	var reader io.Reader
+	switch body := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(body)
+	default:
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+
+	req, _ := http.NewRequest(method, url, reader)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		data, _ := io.ReadAll(resp.Body)
+		t.Fatalf("%s %s: status %d, want %d: %s", method, url, resp.StatusCode, wantStatus, data)
+	}
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: failed to decode response: %v", method, url, err)
+		}
+	}
+}
+
+func hasElement(player PlayerResponse, key string) bool {
+	for _, element := range player.Discovered {
+		if element.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAPIGameplay(t *testing.T) {
+	server := newTestAPI(t)
+
+	var player PlayerResponse
+	doJSON(t, "POST", server.URL+"/players", nil, http.StatusCreated, &player)
+	if len(player.Discovered) != 4 || player.ID == "" {
+		t.Fatalf("New player = %+v, want the four starting elements", player)
+	}
+	playerURL := server.URL + "/players/" + player.ID
+
+	var combine CombineResponse
+	doJSON(t, "POST", playerURL+"/combine", CombineRequest{ElementOne: "Water", ElementTwo: "fire"}, http.StatusOK, &combine)
+	if !combine.Success || !combine.New || combine.Result != "💨 Steam" {
+		t.Errorf("water + fire = %+v, want new Steam", combine)
+	}
+	if len(combine.Achievements) != 1 || combine.Achievements[0].ID != "first-atmospheric" {
+		t.Errorf("water + fire unlocked %+v, want first-atmospheric", combine.Achievements)
+	}
+
+	doJSON(t, "POST", playerURL+"/combine", CombineRequest{ElementOne: "lava", ElementTwo: "fire"}, http.StatusBadRequest, &combine)
+	if combine.Success {
+		t.Errorf("Combining undiscovered lava succeeded")
+	}
+
+	doJSON(t, "POST", playerURL+"/combine", CombineRequest{Elements: []string{"wind", "earth"}}, http.StatusOK, &combine)
+	if combine.Success {
+		t.Errorf("wind + earth = %+v, want failure", combine)
+	}
+
+	doJSON(t, "POST", playerURL+"/combine", CombineRequest{ElementOne: "water"}, http.StatusBadRequest, &combine)
+	if combine.Success {
+		t.Errorf("Combining a single element succeeded")
+	}
+
+	doJSON(t, "GET", playerURL, nil, http.StatusOK, &player)
+	if !hasElement(player, "steam") || player.Attempts != 2 || player.FailedAttempts != 1 {
+		t.Errorf("Player after combining = %+v", player)
+	}
+
+	var tried []TriedResponse
+	doJSON(t, "GET", playerURL+"/tried", nil, http.StatusOK, &tried)
+	if len(tried) != 2 || !slices.Equal(tried[0].Inputs, []string{"earth", "wind"}) || !slices.Equal(tried[1].Results, []string{"steam"}) {
+		t.Errorf("Tried = %+v, want earth + wind and fire + water", tried)
+	}
+
+	doJSON(t, "DELETE", playerURL+"/progress", nil, http.StatusOK, &player)
+	if hasElement(player, "steam") || player.Attempts != 0 {
+		t.Errorf("Player after reset = %+v", player)
+	}
+}
+
+func TestAPIExportImport(t *testing.T) {
+	server := newTestAPI(t)
+
+	var player PlayerResponse
+	doJSON(t, "POST", server.URL+"/players", nil, http.StatusCreated, &player)
+	playerURL := server.URL + "/players/" + player.ID
+
+	doJSON(t, "PUT", playerURL+"/save", []byte(`["water", "fire", "unobtainium"]`), http.StatusBadRequest, nil)
+	doJSON(t, "PUT", playerURL+"/save", []byte(`["water", "fire", "steam"]`), http.StatusOK, &player)
+	if !hasElement(player, "steam") || len(player.Discovered) != 3 {
+		t.Errorf("Player after import = %+v", player)
+	}
+
+	resp, err := http.Get(playerURL + "/save")
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	exported, _ := io.ReadAll(resp.Body)
+	progress, err := craft.DecodeProgress(exported)
+	if err != nil {
+		t.Fatalf("Exported save does not decode: %v", err)
+	}
+	if len(progress.Discovered) != 3 {
+		t.Errorf("Exported %v, want the imported elements", progress.Discovered)
+	}
+}
+
+func TestAPIRejectsInvalidPlayerID(t *testing.T) {
+	server := newTestAPI(t)
+	doJSON(t, "GET", server.URL+"/players/..%2Fprogress", nil, http.StatusBadRequest, nil)
+}
+
+func TestAPIPath(t *testing.T) {
+	server := newTestAPI(t)
+
+	var path PathResponse
+	doJSON(t, "GET", server.URL+"/path?target=Solar+System", nil, http.StatusOK, &path)
+	if len(path.Steps) == 0 || path.Steps[len(path.Steps)-1].Result != "solar-system" {
+		t.Errorf("Path to solar-system = %+v", path)
+	}
+
+	var player PlayerResponse
+	doJSON(t, "POST", server.URL+"/players", nil, http.StatusCreated, &player)
+	doJSON(t, "POST", server.URL+"/players/"+player.ID+"/combine", CombineRequest{ElementOne: "water", ElementTwo: "fire"}, http.StatusOK, nil)
+
+	doJSON(t, "GET", server.URL+"/path?target=steam&player="+player.ID, nil, http.StatusOK, &path)
+	if len(path.Steps) != 0 {
+		t.Errorf("Path to already discovered steam = %+v, want no steps", path)
+	}
+}
+
+func TestAPICombineWithoutPlayer(t *testing.T) {
+	content, err := craft.LoadContent(data.FS)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+	key := craft.RecipeKey("star", "planet")
+	recipe := content.Recipes[key]
+	recipe.Requires = &craft.Requirement{Discoveries: 20}
+	content.Recipes[key] = recipe
+
+	sessions := craft.NewSessions(content, craft.NewMemoryStore(), craft.SourceAPI)
+	server := httptest.NewServer(newAPIHandler(content, sessions, craft.NewRaces(content)))
+	t.Cleanup(server.Close)
+
+	var combine CombineResponse
+	doJSON(t, "GET", server.URL+"/combine?element-one=water&element-two=fire", nil, http.StatusOK, &combine)
+	if !combine.Success || combine.Result != content.Elements["steam"].Name {
+		t.Errorf("water + fire = %+v", combine)
+	}
+
+	combine = CombineResponse{}
+	doJSON(t, "GET", server.URL+"/combine?element-one=star&element-two=planet", nil, http.StatusOK, &combine)
+	if combine.Success || combine.Result != "" || len(combine.Results) != 0 || len(combine.Locked) != 1 || combine.Error != "These elements cannot be combined yet" {
+		t.Errorf("Locked star + planet = %+v", combine)
+	}
+}
+
+func TestAPICategories(t *testing.T) {
+	server := newTestAPI(t)
+
+	var categories []CategoryResponse
+	doJSON(t, "GET", server.URL+"/categories", nil, http.StatusOK, &categories)
+
+	if len(categories) == 0 || categories[0].ID != "primordial" || categories[0].Elements == 0 {
+		t.Errorf("Categories = %+v, want primordial first with elements", categories)
+	}
+}
+
+func TestAPIRace(t *testing.T) {
+	server := newTestAPI(t)
+
+	var ada, grace, alan PlayerResponse
+	doJSON(t, "POST", server.URL+"/players", nil, http.StatusCreated, &ada)
+	doJSON(t, "POST", server.URL+"/players", nil, http.StatusCreated, &grace)
+	doJSON(t, "POST", server.URL+"/players", nil, http.StatusCreated, &alan)
+
+	var race RaceResponse
+	doJSON(t, "POST", server.URL+"/races", RaceRequest{Target: "Steam", Seed: 7}, http.StatusCreated, &race)
+	if race.State != "lobby" || race.Target.Key != "steam" || race.Seed != 7 || race.TimeLimitSeconds != 600 {
+		t.Fatalf("New race = %+v", race)
+	}
+	raceURL := server.URL + "/races/" + race.ID
+
+	doJSON(t, "POST", raceURL+"/racers", JoinRaceRequest{Player: "never-created"}, http.StatusNotFound, nil)
+
+	var adaJoined, graceJoined JoinRaceResponse
+	doJSON(t, "POST", raceURL+"/racers", JoinRaceRequest{Player: ada.ID, Name: "Ada"}, http.StatusOK, &adaJoined)
+	doJSON(t, "POST", raceURL+"/racers", JoinRaceRequest{Player: grace.ID}, http.StatusOK, &graceJoined)
+	if adaJoined.Token == "" || graceJoined.Token == adaJoined.Token || adaJoined.Index != 0 || graceJoined.Index != 1 {
+		t.Errorf("Joined as %+v and %+v, want two tokens and indexes 0 and 1", adaJoined, graceJoined)
+	}
+	race = graceJoined.Race
+	if len(race.Racers) != 2 || race.Racers[1].Name != "Racer 2" || race.Racers[1].Index != 1 {
+		t.Errorf("Racers = %+v, want Ada and Racer 2", race.Racers)
+	}
+	doJSON(t, "POST", raceURL+"/combine", RaceCombineRequest{Token: adaJoined.Token, ElementOne: "water", ElementTwo: "fire"}, http.StatusConflict, nil)
+
+	doJSON(t, "POST", raceURL+"/start", nil, http.StatusOK, &race)
+	if race.State != "running" {
+		t.Errorf("Race after starting = %+v", race)
+	}
+	doJSON(t, "POST", raceURL+"/racers", JoinRaceRequest{Player: alan.ID}, http.StatusConflict, nil)
+	for _, token := range []string{"", ada.ID, apiPlayer(ada.ID)} {
+		doJSON(t, "POST", raceURL+"/combine", RaceCombineRequest{Token: token, ElementOne: "water", ElementTwo: "fire"}, http.StatusForbidden, nil)
+	}
+	doJSON(t, "POST", raceURL+"/combine", RaceCombineRequest{Token: adaJoined.Token, ElementOne: "steam", ElementTwo: "fire"}, http.StatusBadRequest, nil)
+
+	var combine RaceCombineResponse
+	doJSON(t, "POST", raceURL+"/combine", RaceCombineRequest{Token: adaJoined.Token, Elements: []string{"earth", "wind"}}, http.StatusOK, &combine)
+	if combine.Result.Success || combine.Race.State != "running" || combine.Race.Winner != nil {
+		t.Errorf("earth + wind = %+v", combine)
+	}
+	doJSON(t, "POST", raceURL+"/combine", RaceCombineRequest{Token: graceJoined.Token, ElementOne: "water", ElementTwo: "fire"}, http.StatusOK, &combine)
+	if !combine.Result.Success || combine.Race.State != "finished" || combine.Race.Winner == nil || *combine.Race.Winner != 1 {
+		t.Errorf("Winning combination = %+v", combine)
+	}
+	doJSON(t, "POST", raceURL+"/combine", RaceCombineRequest{Token: adaJoined.Token, ElementOne: "water", ElementTwo: "fire"}, http.StatusConflict, nil)
+
+	resp, err := http.Get(raceURL)
+	if err != nil {
+		t.Fatalf("GET race: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	for _, secret := range []string{ada.ID, grace.ID, adaJoined.Token, graceJoined.Token} {
+		if bytes.Contains(body, []byte(secret)) {
+			t.Errorf("Race %s reveals %q", body, secret)
+		}
+	}
+
+	race = RaceResponse{}
+	if err := json.Unmarshal(body, &race); err != nil {
+		t.Fatalf("Invalid race: %v", err)
+	}
+	if winner := race.Racers[0]; winner.Name != "Racer 2" || winner.Index != 1 || !winner.Finished || winner.Attempts != 1 {
+		t.Errorf("Standings = %+v, want Racer 2 first", race.Racers)
+	}
+	if runnerUp := race.Racers[1]; runnerUp.Name != "Ada" || runnerUp.Finished || runnerUp.Attempts != 1 || runnerUp.TimeSeconds != 0 {
+		t.Errorf("Standings = %+v, want Ada second", race.Racers)
+	}
+
+	doJSON(t, "POST", server.URL+"/races", RaceRequest{Target: "ghost"}, http.StatusBadRequest, nil)
+	doJSON(t, "GET", server.URL+"/races/NOPE0", nil, http.StatusNotFound, nil)
+}
+
+func TestAPIReportsRestoredSave(t *testing.T) {
+	content, err := craft.LoadContent(data.FS)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+	store := craft.NewFileStore(t.TempDir())
+
+	progress := craft.NewProgress()
+	progress.Discovered = slices.Clone(craft.StartingElements)
+	store.Save(apiPlayer("ada"), progress)
+	store.Save(apiPlayer("ada"), progress)
+	path, _ := store.Path(apiPlayer("ada"))
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := craft.NewSessions(content, store, craft.SourceAPI)
+	server := httptest.NewServer(newAPIHandler(content, sessions, craft.NewRaces(content)))
+	defer server.Close()
+
+	var player PlayerResponse
+	doJSON(t, "GET", server.URL+"/players/ada", nil, http.StatusOK, &player)
+	if player.Restored == "" || len(player.Discovered) != 4 {
+		t.Errorf("Player = %+v, want restored progress", player)
+	}
+	player = PlayerResponse{}
+	doJSON(t, "GET", server.URL+"/players/ada", nil, http.StatusOK, &player)
+	if player.Restored != "" {
+		t.Errorf("Restored save reported twice: %q", player.Restored)
+	}
+}
+
+func TestAPIUnknownPlayer(t *testing.T) {
+	content, err := craft.LoadContent(data.FS)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+	store := craft.NewMemoryStore()
+	sessions := craft.NewSessions(content, store, craft.SourceAPI)
+	server := httptest.NewServer(newAPIHandler(content, sessions, craft.NewRaces(content)))
+	defer server.Close()
+
+	doJSON(t, "GET", server.URL+"/players/never-created", nil, http.StatusNotFound, nil)
+	doJSON(t, "POST", server.URL+"/players/never-created/combine", CombineRequest{ElementOne: "water", ElementTwo: "fire"}, http.StatusNotFound, nil)
+	doJSON(t, "GET", server.URL+"/path?target=steam&player=never-created", nil, http.StatusNotFound, nil)
+
+	if players, _ := store.List(); len(players) != 0 || sessions.Len() != 0 {
+		t.Errorf("Unknown player lookups created players %v", players)
+	}
+}
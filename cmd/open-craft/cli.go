@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tracepanic/open-craft/craft"
+)
+
+func clearScreen() {
+	switch runtime.GOOS {
+	case "windows":
+		cmd := exec.Command("cmd", "/c", "cls")
+		cmd.Stdout = os.Stdout
+		cmd.Run()
+	default:
+		fmt.Print("\033[H\033[2J")
+	}
+}
+
+func getInput(prompt string, scanner *bufio.Scanner) string {
+	fmt.Print(prompt)
+	scanner.Scan()
+	return strings.TrimSpace(scanner.Text())
+}
+
+func printSlowly(text string, delay time.Duration) {
+	for _, char := range text {
+		fmt.Print(string(char))
+		time.Sleep(delay)
+	}
+	fmt.Println()
+}
+
+func formatPath(content *craft.Content, steps []craft.Step) string {
+	if len(steps) == 0 {
+		return "You already have it!"
+	}
+
+	var path strings.Builder
+	for i, step := range steps {
+		inputs := make([]string, 0, len(step.Inputs))
+		for _, input := range step.Inputs {
+			inputs = append(inputs, content.Elements[input].Name)
+		}
+
+		path.WriteString(fmt.Sprintf("%d. %s = %s", i+1,
+			strings.Join(inputs, " + "),
+			content.Elements[step.Result].Name))
+		if step.Random {
+			path.WriteString(" (sometimes)")
+		}
+		path.WriteString("\n")
+	}
+	return strings.TrimSuffix(path.String(), "\n")
+}
+
+// formatDuration rounds d to the second, leaving out zero seconds after
+// whole minutes.
+func formatDuration(d time.Duration) string {
+	text := d.Round(time.Second).String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	return text
+}
+
+func formatResults(content *craft.Content, results []string) string {
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, content.Elements[result].Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func describeAchievement(content *craft.Content, id string) string {
+	achievement, _ := content.Achievement(id)
+	return fmt.Sprintf("🏆 Achievement unlocked: %s (%s)", achievement.Name, achievement.Description)
+}
+
+// requestHint asks the hint engine for the next hint, saves the updated hint
+// usage and returns the message to show the player.
+func requestHint(gameState *craft.GameState, cooldown time.Duration) string {
+	hint, err := gameState.Hint(time.Now(), cooldown)
+
+	var wait *craft.HintCooldownError
+	switch {
+	case errors.As(err, &wait):
+		return fmt.Sprintf("⏳ Hints need time to recharge. Try again in %s.", wait.Remaining.Round(time.Second))
+	case err != nil:
+		return "🎉 You've made everything you can with your elements for now!"
+	}
+
+	if err := gameState.Save(); err != nil {
+		log.Printf("Failed to save hint usage: %v", err)
+	}
+	return describeHint(gameState.Content, hint)
+}
+
+func describeHint(content *craft.Content, hint craft.Hint) string {
+	names := make([]string, 0, len(hint.Inputs))
+	for _, input := range hint.Inputs {
+		names = append(names, content.Elements[input].Name)
+	}
+
+	switch hint.Level {
+	case craft.HintCategory:
+		category, _ := content.Category(hint.Category)
+		return fmt.Sprintf("💡 Try something with a %s element. Ask again for a bigger hint.", category.Label())
+	case craft.HintOneInput:
+		return fmt.Sprintf("💡 Try combining %s with something. Ask again for a bigger hint.", names[0])
+	default:
+		return fmt.Sprintf("💡 Try combining %s.", strings.Join(names, " + "))
+	}
+}
+
+// describeCombine is the message shown to the player after combining.
+func describeCombine(content *craft.Content, result craft.CombineResult) string {
+	switch {
+	case result.Success():
+		message := fmt.Sprintf("✨ You created: %s!", formatResults(content, result.Results))
+		for _, id := range result.Achievements {
+			message += "\n" + describeAchievement(content, id)
+		}
+		return message
+	case len(result.Locked) > 0:
+		return fmt.Sprintf("🔒 Something stirs, but first you need to %s.", strings.Join(result.Locked, " and "))
+	case result.Repeated:
+		return "❌ You already tried this: these elements cannot be combined."
+	default:
+		return "❌ These elements cannot be combined."
+	}
+}
+
+// runRace races against the clock to craft a target picked from a seed.
+// Racing with the same seed gives the same target and the same luck, so
+// players can compare their times.
+func runRace(content *craft.Content, scanner *bufio.Scanner) {
+	fmt.Println("\n=== Race ===")
+
+	var seed uint64
+	if input := getInput("\nSeed (Enter for a random one): ", scanner); input != "" {
+		parsed, err := strconv.ParseUint(input, 10, 64)
+		if err != nil {
+			printSlowly("❌ A seed is a whole number.", 30*time.Millisecond)
+			time.Sleep(time.Second)
+			return
+		}
+		seed = parsed
+	}
+
+	race, err := craft.NewRace(content, "", seed, craft.DefaultRaceLimit)
+	if err != nil {
+		fmt.Printf("\n❌ %v\n", err)
+		getInput("\nPress Enter to continue...", scanner)
+		return
+	}
+	race.Join(localPlayer, "You")
+
+	target := content.Elements[race.Target].Name
+	fmt.Printf("\n🏁 Craft %s as fast as you can! You have %s.\n", target, formatDuration(race.Limit))
+	fmt.Printf("Seed %d: friends racing with the same seed get the same target and the same luck.\n", race.Seed)
+	getInput("\nPress Enter to start...", scanner)
+	race.Start(time.Now())
+
+	for {
+		status := race.Status(time.Now())
+		if status.State != craft.RaceRunning {
+			break
+		}
+
+		discovered, _ := race.Discovered(localPlayer)
+		fmt.Printf("\n⏱️ %s left to craft %s\n", formatDuration(status.Remaining()), target)
+		fmt.Printf("Elements: %s\n", formatResults(content, discovered))
+
+		input := getInput("Elements to combine (e.g. water fire), or q to give up: ", scanner)
+		if input == "q" {
+			break
+		}
+
+		names := splitElements(input)
+		if len(names) < 2 || len(names) > content.MaxInputs() {
+			fmt.Printf("❌ Name between 2 and %d elements.\n", content.MaxInputs())
+			continue
+		}
+
+		var inputs []string
+		for _, name := range names {
+			element, ok := content.MatchElement(name, discovered)
+			if !ok {
+				fmt.Printf("❌ You haven't discovered anything called %q yet!\n", name)
+				break
+			}
+			inputs = append(inputs, element)
+		}
+		if len(inputs) < len(names) {
+			continue
+		}
+
+		result, err := race.Combine(localPlayer, time.Now(), inputs...)
+		if err != nil {
+			break
+		}
+		fmt.Println(describeCombine(content, result))
+	}
+
+	status := race.Status(time.Now())
+	switch status.State {
+	case craft.RaceFinished:
+		standing := status.Standings[0]
+		printSlowly(fmt.Sprintf("\n🏆 You crafted %s in %s with %d attempts! (seed %d)",
+			target, formatDuration(standing.Time), standing.Attempts, race.Seed), 30*time.Millisecond)
+	case craft.RaceExpired:
+		printSlowly(fmt.Sprintf("\n⏰ Time's up! %s will have to wait for another race.", target), 30*time.Millisecond)
+	default:
+		printSlowly("\n🏳️ You gave up the race.", 30*time.Millisecond)
+	}
+	getInput("\nPress Enter to continue...", scanner)
+}
+
+// runCLI plays the game in the terminal. In dev mode, reload is used by the
+// recipe creator flow to pick up edits to the data files.
+func runCLI(gameState *craft.GameState, store craft.ProgressStore, hintCooldown time.Duration, devMode bool, reload func() (*craft.Content, error), scanner *bufio.Scanner) {
+	if gameState.Restored != nil {
+		fmt.Printf("\n⚠️ %v\n", gameState.Restored)
+		getInput("\nPress Enter to continue...", scanner)
+		gameState.Restored = nil
+	}
+
+	for {
+		clearScreen()
+		fmt.Println("\n🌟 === Open Craft === 🌟")
+		fmt.Printf("\nDiscovered Elements: %d/%d\n", len(gameState.Discovered), len(gameState.Elements))
+		if len(gameState.Achievements) > 0 {
+			fmt.Printf("Achievements: %d/%d\n", len(gameState.Unlocked), len(gameState.Achievements))
+		}
+
+		fmt.Println("\n1. 🔮 Combine Elements")
+		fmt.Println("2. 📚 View Discovered Elements")
+		fmt.Println("3. 💡 Show Hints")
+		fmt.Println("4. 💾 Save and Exit")
+		fmt.Println("5. 🏁 Race Against the Clock")
+		if devMode {
+			fmt.Println("6. 🔍 View Untried Combinations (Dev)")
+			fmt.Println("7. ⚡ Recipe Creator Flow (Dev)")
+			fmt.Println("8. 🧭 Find Crafting Path (Dev)")
+		}
+
+		choice := getInput("\nChoose an option: ", scanner)
+
+		switch choice {
+		case "1":
+			fmt.Println("\n=== Available Elements ===")
+
+			for _, name := range gameState.SortedDiscovered() {
+				fmt.Printf("- %s\n", gameState.Elements[name].Name)
+			}
+
+			inputs := []string{
+				craft.NormalizeElementName(getInput("\nFirst element: ", scanner)),
+				craft.NormalizeElementName(getInput("Second element: ", scanner)),
+			}
+			for len(inputs) < gameState.MaxInputs() {
+				input := craft.NormalizeElementName(getInput("Another element (Enter to combine): ", scanner))
+				if input == "" {
+					break
+				}
+				inputs = append(inputs, input)
+			}
+
+			if !slices.ContainsFunc(inputs, func(input string) bool { return !gameState.IsDiscovered(input) }) {
+				result := gameState.Combine(inputs...)
+				printSlowly(describeCombine(gameState.Content, result), 30*time.Millisecond)
+				gameState.Save()
+			} else {
+				printSlowly("❌ You haven't discovered one or more of these elements yet!", 30*time.Millisecond)
+			}
+			time.Sleep(2 * time.Second)
+
+		case "2":
+			fmt.Println("\n=== Discovered Elements ===")
+
+			discovered := gameState.SortedDiscovered()
+			for _, category := range gameState.Categories {
+				var names []string
+				for _, name := range discovered {
+					if gameState.Elements[name].Category == category.ID {
+						names = append(names, gameState.Elements[name].Name)
+					}
+				}
+				if len(names) == 0 {
+					continue
+				}
+
+				fmt.Printf("\n%s\n", category.Label())
+				for _, name := range names {
+					fmt.Printf("- %s\n", name)
+				}
+			}
+
+			getInput("\nPress Enter to continue...", scanner)
+
+		case "3":
+			fmt.Println("\n=== Hints ===")
+			fmt.Println(requestHint(gameState, hintCooldown))
+			getInput("\nPress Enter to continue...", scanner)
+
+		case "4":
+			if err := gameState.Save(); err != nil {
+				fmt.Printf("Failed to save progress: %v\n", err)
+			}
+			printSlowly("Thanks for playing! Your progress has been saved.", 30*time.Millisecond)
+			return
+
+		case "5":
+			runRace(gameState.Content, scanner)
+
+		case "6":
+			if devMode {
+				fmt.Println("\n=== Untried Combinations ===")
+				tried, err := craft.CountTried(store)
+				if err != nil {
+					fmt.Printf("Error reading player attempts: %v\n", err)
+				}
+				combos := gameState.UntriedCombos(tried)
+				if len(combos) == 0 {
+					fmt.Println("You've tried all possible combinations!")
+				} else {
+					fmt.Printf("\nFound %d untried combinations:\n\n", len(combos))
+					for _, combo := range combos {
+						fmt.Println(combo)
+					}
+				}
+				getInput("\nPress Enter to continue...", scanner)
+			} else {
+				printSlowly("Invalid choice.", 30*time.Millisecond)
+				time.Sleep(time.Second)
+			}
+
+		case "7":
+			if devMode {
+				for {
+					clearScreen()
+					fmt.Println("\n=== Recipe Creator Flow ===")
+
+					content, err := reload()
+					if err != nil {
+						fmt.Printf("Error reloading game state: %v\n", err)
+						getInput("\nPress Enter to return to main menu...", scanner)
+						break
+					}
+
+					gameState.Content = content
+					tried, err := craft.CountTried(store)
+					if err != nil {
+						fmt.Printf("Error reading player attempts: %v\n", err)
+					}
+					combos := gameState.UntriedCombos(tried)
+					remainingCount := len(combos)
+
+					if remainingCount == 0 {
+						fmt.Println("\nNo more combinations available to create recipes for!")
+						getInput("\nPress Enter to return to main menu...", scanner)
+						break
+					}
+
+					var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+					randomIndex := rng.Intn(len(combos))
+					combo := combos[randomIndex]
+
+					fmt.Printf("\nRemaining possible combinations: %d\n\n", remainingCount)
+					fmt.Printf("Suggested combination to create recipe for:\n%s\n\n", combo)
+
+					fmt.Println("Options:")
+					fmt.Println("1. Next combination")
+					fmt.Println("2. Return to main menu")
+
+					subchoice := getInput("\nChoice: ", scanner)
+
+					if subchoice != "1" {
+						break
+					}
+				}
+			}
+
+		case "8":
+			if devMode {
+				fmt.Println("\n=== Find Crafting Path ===")
+				target := craft.NormalizeElementName(getInput("\nTarget element: ", scanner))
+
+				steps, err := gameState.ShortestPath(craft.StartingElements, target)
+				if err != nil {
+					fmt.Printf("\n❌ %v\n", err)
+				} else {
+					fmt.Printf("\nShortest path from the starting elements (%d steps):\n\n", len(steps))
+					fmt.Println(formatPath(gameState.Content, steps))
+				}
+				getInput("\nPress Enter to continue...", scanner)
+			} else {
+				printSlowly("Invalid choice.", 30*time.Millisecond)
+				time.Sleep(time.Second)
+			}
+		default:
+			printSlowly("Invalid choice.", 30*time.Millisecond)
+			time.Sleep(time.Second)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tracepanic/open-craft/craft"
+)
+
+// Service states reported by the admin health endpoint.
+const (
+	stateStarting = "starting"
+	stateRunning  = "running"
+	stateStopping = "stopping"
+	stateStopped  = "stopped"
+	stateFailed   = "failed"
+)
+
+// shutdownTimeout bounds how long the HTTP servers wait for requests in
+// flight when shutting down.
+const shutdownTimeout = 10 * time.Second
+
+// daemon runs the HTTP API, the Telegram bot and the admin endpoint in one
+// process over one content set and progress store. Servers that share an
+// address share one listener.
+type daemon struct {
+	content *craft.Content
+	store   craft.ProgressStore
+
+	apiAddr   string
+	adminAddr string
+
+	// bot is nil unless the Telegram bot runs. It polls for updates
+	// unless webhook is set.
+	bot           *TelegramBot
+	webhook       string
+	webhookURL    string
+	webhookSecret string
+
+	apiSessions *craft.Sessions
+	listeners   map[string]net.Listener
+	muxes       map[string]*http.ServeMux
+	started     time.Time
+
+	// mu guards services.
+	mu       sync.Mutex
+	services map[string]*ServiceHealth
+}
+
+type ServiceHealth struct {
+	State string `json:"state"`
+	Addr  string `json:"addr,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type HealthResponse struct {
+	Status        string                   `json:"status"`
+	UptimeSeconds int64                    `json:"uptime_seconds"`
+	Services      map[string]ServiceHealth `json:"services"`
+	Players       map[string]int           `json:"players"`
+}
+
+func (d *daemon) setState(service, state string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	health := d.services[service]
+	health.State = state
+	if err != nil {
+		health.Error = err.Error()
+	}
+}
+
+// transition moves every service in state from to state to.
+func (d *daemon) transition(from, to string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, health := range d.services {
+		if health.State == from {
+			health.State = to
+		}
+	}
+}
+
+// failures returns an error for every service that failed.
+func (d *daemon) failures() []error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(d.services)) {
+		if health := d.services[name]; health.State == stateFailed {
+			errs = append(errs, fmt.Errorf("%s failed: %s", name, health.Error))
+		}
+	}
+	return errs
+}
+
+// mux returns the mux served on addr, creating it on first use.
+func (d *daemon) mux(addr string) *http.ServeMux {
+	if mux, exists := d.muxes[addr]; exists {
+		return mux
+	}
+	mux := http.NewServeMux()
+	d.muxes[addr] = mux
+	return mux
+}
+
+// listen registers every service and binds every address, so that a taken
+// port is reported before anything starts.
+func (d *daemon) listen() error {
+	d.muxes = make(map[string]*http.ServeMux)
+	d.listeners = make(map[string]net.Listener)
+	d.services = make(map[string]*ServiceHealth)
+
+	// The API and the bot share race lobbies, so that players of either
+	// can race each other.
+	races := craft.NewRaces(d.content)
+	if d.bot != nil {
+		races = d.bot.races
+	}
+
+	if d.apiAddr != "" {
+		d.apiSessions = craft.NewSessions(d.content, d.store, craft.SourceAPI)
+		d.mux(d.apiAddr).Handle("/", newAPIHandler(d.content, d.apiSessions, races))
+		d.services["api"] = &ServiceHealth{State: stateStarting, Addr: d.apiAddr}
+	}
+
+	if d.bot != nil {
+		health := &ServiceHealth{State: stateStarting}
+		if d.webhook != "" {
+			addr, path, err := parseWebhookAddr(d.webhook)
+			if err != nil {
+				return err
+			}
+			d.mux(addr).Handle(path, d.bot.WebhookHandler(d.webhookSecret))
+			health.Addr = d.webhook
+		}
+		d.services["bot"] = health
+	}
+
+	if d.adminAddr != "" {
+		d.mux(d.adminAddr).HandleFunc("GET /health", d.handleHealth)
+		d.services["admin"] = &ServiceHealth{State: stateStarting, Addr: d.adminAddr}
+	}
+
+	for addr := range d.muxes {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, listener := range d.listeners {
+				listener.Close()
+			}
+			return err
+		}
+		d.listeners[addr] = listener
+	}
+	return nil
+}
+
+// servicesOn returns the services served on addr.
+func (d *daemon) servicesOn(addr string) []string {
+	var services []string
+	if d.apiAddr == addr {
+		services = append(services, "api")
+	}
+	if d.bot != nil && d.webhook != "" {
+		if webhookAddr, _, _ := parseWebhookAddr(d.webhook); webhookAddr == addr {
+			services = append(services, "bot")
+		}
+	}
+	if d.adminAddr == addr {
+		services = append(services, "admin")
+	}
+	return services
+}
+
+// serve runs every service until ctx is cancelled or one of them fails, then
+// shuts them all down and saves every player. listen must be called first.
+func (d *daemon) serve(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	d.started = time.Now()
+
+	var wg sync.WaitGroup
+	var servers []*http.Server
+	for addr, listener := range d.listeners {
+		server := &http.Server{Handler: d.muxes[addr]}
+		servers = append(servers, server)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+				for _, service := range d.servicesOn(addr) {
+					d.setState(service, stateFailed, err)
+				}
+				cancel()
+			}
+		}()
+	}
+
+	if d.bot != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var err error
+			if d.webhook != "" {
+				err = d.bot.StartWebhook(ctx, d.webhookURL, d.webhookSecret)
+			} else {
+				err = d.bot.Start(ctx)
+			}
+			if err != nil {
+				d.setState("bot", stateFailed, err)
+				cancel()
+			}
+		}()
+	}
+
+	d.transition(stateStarting, stateRunning)
+	<-ctx.Done()
+	d.transition(stateRunning, stateStopping)
+
+	shutdownCtx, stop := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer stop()
+	var errs []error
+	for _, server := range servers {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	wg.Wait()
+
+	if d.apiSessions != nil {
+		if err := d.apiSessions.SaveAll(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to save API players: %w", err))
+		}
+	}
+
+	d.transition(stateStopping, stateStopped)
+	return errors.Join(append(d.failures(), errs...)...)
+}
+
+// run starts every service and blocks until ctx is cancelled or one of them
+// fails.
+func (d *daemon) run(ctx context.Context) error {
+	if err := d.listen(); err != nil {
+		return err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(d.services)) {
+		if addr := d.services[name].Addr; addr != "" {
+			log.Printf("Starting %s on %s", name, addr)
+		} else {
+			log.Printf("Starting %s", name)
+		}
+	}
+	return d.serve(ctx)
+}
+
+// health reports the state of every service. The daemon is healthy while
+// every service is running.
+func (d *daemon) health() HealthResponse {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	response := HealthResponse{
+		Status:        "ok",
+		UptimeSeconds: int64(time.Since(d.started).Seconds()),
+		Services:      make(map[string]ServiceHealth, len(d.services)),
+		Players:       make(map[string]int),
+	}
+	for name, health := range d.services {
+		response.Services[name] = *health
+		if health.State != stateRunning {
+			response.Status = "unavailable"
+		}
+	}
+
+	if d.apiSessions != nil {
+		response.Players["api"] = d.apiSessions.Len()
+	}
+	if d.bot != nil {
+		response.Players["telegram"] = d.bot.sessions.Len()
+	}
+	return response
+}
+
+func (d *daemon) handleHealth(w http.ResponseWriter, r *http.Request) {
+	response := d.health()
+
+	status := http.StatusOK
+	if response.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, response)
+}
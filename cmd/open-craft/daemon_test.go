@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tracepanic/open-craft/craft"
+)
+
+// startDaemon binds the daemon's addresses and serves it until the test
+// ends, returning the address the API is served on.
+func startDaemon(t *testing.T, d *daemon) (string, context.CancelFunc, <-chan error) {
+	t.Helper()
+
+	if err := d.listen(); err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- d.serve(ctx) }()
+	t.Cleanup(cancel)
+
+	return "http://" + d.listeners[d.apiAddr].Addr().String(), cancel, done
+}
+
+func waitStopped(t *testing.T, done <-chan error) {
+	t.Helper()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serve returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after cancellation")
+	}
+}
+
+func TestDaemonRunsEveryService(t *testing.T) {
+	fake := newFakeBotAPI(t)
+	store := craft.NewMemoryStore()
+	bot := newTestTelegramBot(t, fake, store)
+
+	d := &daemon{
+		content:   bot.content,
+		store:     store,
+		apiAddr:   "127.0.0.1:0",
+		adminAddr: "127.0.0.1:0",
+		bot:       bot,
+	}
+	url, cancel, done := startDaemon(t, d)
+
+	var player PlayerResponse
+	doJSON(t, http.MethodPost, url+"/players", nil, http.StatusCreated, &player)
+	fake.push(5, "/progress")
+	fake.waitFor(t, 5, "📊 Discovered Elements")
+
+	var health HealthResponse
+	doJSON(t, http.MethodGet, url+"/health", nil, http.StatusOK, &health)
+	if health.Status != "ok" || len(health.Services) != 3 {
+		t.Errorf("Health = %+v, want three services running", health)
+	}
+	for name, service := range health.Services {
+		if service.State != stateRunning {
+			t.Errorf("Service %s is %s, want running", name, service.State)
+		}
+	}
+	if health.Players["api"] != 1 || health.Players["telegram"] != 1 {
+		t.Errorf("Players = %v, want one of each", health.Players)
+	}
+
+	// Races opened through the API can be joined from Telegram.
+	var race RaceResponse
+	doJSON(t, http.MethodPost, url+"/races", RaceRequest{}, http.StatusCreated, &race)
+	fake.push(5, "/race join "+race.ID)
+	fake.waitFor(t, 5, "joined race "+race.ID)
+
+	cancel()
+	waitStopped(t, done)
+
+	for _, player := range []string{apiPlayer(player.ID), telegramPlayer(5)} {
+		if _, err := store.Load(player); err != nil {
+			t.Errorf("Progress of %s was not saved: %v", player, err)
+		}
+	}
+	if health := d.health(); health.Status == "ok" || health.Services["api"].State != stateStopped {
+		t.Errorf("Health after shutdown = %+v, want stopped", health)
+	}
+}
+
+func TestDaemonSharesWebhookWithAPI(t *testing.T) {
+	fake := newFakeBotAPI(t)
+	store := craft.NewMemoryStore()
+	bot := newTestTelegramBot(t, fake, store)
+
+	d := &daemon{
+		content:       bot.content,
+		store:         store,
+		apiAddr:       "127.0.0.1:0",
+		bot:           bot,
+		webhook:       "127.0.0.1:0/telegram",
+		webhookSecret: "s3cret",
+	}
+	url, cancel, done := startDaemon(t, d)
+
+	if len(d.listeners) != 1 {
+		t.Errorf("Daemon listens on %d addresses, want 1", len(d.listeners))
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, url+"/telegram", strings.NewReader(
+		`{"update_id": 1, "message": {"message_id": 1, "chat": {"id": 9, "type": "private"}, "text": "🏠 Main Menu"}}`))
+	req.Header.Set(webhookSecretHeader, "s3cret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /telegram failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("POST /telegram: status %d, want 200", resp.StatusCode)
+	}
+	fake.waitFor(t, 9, "Choose an option:")
+
+	var categories []CategoryResponse
+	doJSON(t, http.MethodGet, url+"/categories", nil, http.StatusOK, &categories)
+
+	cancel()
+	waitStopped(t, done)
+}
+
+func TestDaemonReportsTakenPort(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer taken.Close()
+
+	fake := newFakeBotAPI(t)
+	store := craft.NewMemoryStore()
+	bot := newTestTelegramBot(t, fake, store)
+
+	d := &daemon{
+		content:   bot.content,
+		store:     store,
+		apiAddr:   "127.0.0.1:0",
+		adminAddr: taken.Addr().String(),
+		bot:       bot,
+	}
+	if err := d.run(context.Background()); err == nil {
+		t.Fatal("run succeeded with a taken port")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.sent) != 0 {
+		t.Errorf("Bot started despite the taken port: %+v", fake.sent)
+	}
+}
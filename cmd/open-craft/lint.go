@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tracepanic/open-craft/craft"
+)
+
+type LintReport struct {
+	Issues   []craft.Issue `json:"issues"`
+	Errors   int           `json:"errors"`
+	Warnings int           `json:"warnings"`
+}
+
+// runLint implements the lint subcommand. It exits with 1 if any errors are
+// found and 2 on invalid usage.
+func runLint(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dir := flags.String("dir", "data", "Directory containing the game data")
+	format := flags.String("format", "text", "Output format (text or json)")
+	strict := flags.Bool("strict", false, "Treat warnings as errors")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "Unknown format %q\n", *format)
+		return 2
+	}
+
+	report := LintReport{Issues: craft.Lint(os.DirFS(*dir))}
+	if report.Issues == nil {
+		report.Issues = make([]craft.Issue, 0)
+	}
+
+	for _, issue := range report.Issues {
+		if issue.Severity == craft.SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		for _, issue := range report.Issues {
+			fmt.Fprintln(stdout, issue)
+		}
+		fmt.Fprintf(stdout, "%d errors, %d warnings\n", report.Errors, report.Warnings)
+	}
+
+	if report.Errors > 0 || (*strict && report.Warnings > 0) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tracepanic/open-craft/craft"
+)
+
+// writeLintData writes game data to a temporary directory, replacing the
+// impossible combinations with impossible.
+func writeLintData(t *testing.T, impossible string) string {
+	t.Helper()
+
+	files := map[string]string{
+		"categories.json": `[{"id": "primordial", "name": "Primordial", "emoji": "🌟", "order": 1}]`,
+		"elements.json": `{
+			"water": {"name": "Water", "category": "primordial"},
+			"fire": {"name": "Fire", "category": "primordial"},
+			"earth": {"name": "Earth", "category": "primordial"},
+			"wind": {"name": "Wind", "category": "primordial"},
+			"steam": {"name": "Steam", "category": "primordial"}
+		}`,
+		"recipes.json":    `{"water+fire": "steam"}`,
+		"impossible.json": impossible,
+	}
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRunLint(t *testing.T) {
+	clean := writeLintData(t, `["earth+wind"]`)
+	warning := writeLintData(t, `["earth+wind", "wind+earth"]`)
+	broken := writeLintData(t, `["earth+ghost"]`)
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"clean", []string{"-dir", clean}, 0},
+		{"warnings", []string{"-dir", warning}, 0},
+		{"strict warnings", []string{"-dir", warning, "-strict"}, 1},
+		{"errors", []string{"-dir", broken}, 1},
+		{"unknown flag", []string{"-dir", clean, "-fix"}, 2},
+		{"unknown format", []string{"-dir", clean, "-format", "xml"}, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := runLint(test.args, &stdout, &stderr); got != test.want {
+				t.Errorf("runLint(%v) = %d, want %d\nstdout:\n%s\nstderr:\n%s", test.args, got, test.want, &stdout, &stderr)
+			}
+		})
+	}
+}
+
+func TestRunLintJSON(t *testing.T) {
+	dir := writeLintData(t, `["earth+wind", "wind+earth", "earth+ghost"]`)
+
+	var stdout, stderr bytes.Buffer
+	if got := runLint([]string{"-dir", dir, "-format", "json"}, &stdout, &stderr); got != 1 {
+		t.Errorf("runLint = %d, want 1", got)
+	}
+
+	var report LintReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("Output is not a LintReport: %v\n%s", err, &stdout)
+	}
+	if report.Errors != 1 || report.Warnings != 1 || len(report.Issues) != 2 {
+		t.Errorf("report = %+v, want 1 error and 1 warning", report)
+	}
+	for _, issue := range report.Issues {
+		if issue.File != "impossible.json" {
+			t.Errorf("Issue %v is not in impossible.json", issue)
+		}
+		if issue.Severity != craft.SeverityError && issue.Severity != craft.SeverityWarning {
+			t.Errorf("Issue %v has unknown severity", issue)
+		}
+	}
+}
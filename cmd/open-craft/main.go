@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tracepanic/open-craft/craft"
+	"github.com/tracepanic/open-craft/data"
+)
+
+const localPlayer = "progress"
+
+func getConfigDir() (string, error) {
+	var configDir string
+	switch runtime.GOOS {
+	case "windows":
+		configDir = filepath.Join(os.Getenv("APPDATA"), "open-craft")
+	default:
+		userConfigDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		configDir = filepath.Join(userConfigDir, "open-craft")
+	}
+
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return configDir, nil
+}
+
+func contentFS(dev bool) fs.FS {
+	if dev {
+		return os.DirFS("data")
+	}
+	return data.FS
+}
+
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// loadContent loads the base game and layers the given content packs over it.
+func loadContent(dev bool, packPaths []string) (*craft.Content, error) {
+	content, err := craft.LoadContent(contentFS(dev))
+	if err != nil {
+		return nil, err
+	}
+
+	packs := make([]*craft.Pack, 0, len(packPaths))
+	for _, path := range packPaths {
+		pack, err := craft.OpenPack(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load pack %s: %w", path, err)
+		}
+		packs = append(packs, pack)
+	}
+
+	if err := content.ApplyPacks(packs...); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+func openStore(kind, configDir string) (craft.ProgressStore, error) {
+	switch kind {
+	case "file":
+		return craft.NewFileStore(configDir), nil
+	case "bolt":
+		return craft.OpenBoltStore(filepath.Join(configDir, "progress.db"))
+	default:
+		return nil, fmt.Errorf("unknown progress store %q", kind)
+	}
+}
+
+func printValidation(content *craft.Content) bool {
+	analysis := content.Analyze(craft.StartingElements)
+
+	depths := make(map[int][]string)
+	maxDepth := 0
+	for element, depth := range analysis.Depth {
+		depths[depth] = append(depths[depth], element)
+		maxDepth = max(maxDepth, depth)
+	}
+
+	fmt.Println("=== Depth ===")
+	for depth := 0; depth <= maxDepth; depth++ {
+		sort.Strings(depths[depth])
+		fmt.Printf("%d: %s\n", depth, strings.Join(depths[depth], ", "))
+	}
+
+	fmt.Printf("\n=== Unreachable Elements (%d) ===\n", len(analysis.Unreachable))
+	for _, element := range analysis.Unreachable {
+		fmt.Printf("- %s\n", element)
+	}
+
+	fmt.Printf("\n=== Dead-End Elements (%d) ===\n", len(analysis.DeadEnds))
+	for _, element := range analysis.DeadEnds {
+		fmt.Printf("- %s\n", element)
+	}
+
+	fmt.Printf("\n=== Cycles (%d) ===\n", len(analysis.Cycles))
+	for _, cycle := range analysis.Cycles {
+		fmt.Printf("- %s\n", cycle)
+		if len(cycle.Elements) > 1 {
+			fmt.Printf("  (%d elements: %s)\n", len(cycle.Elements), strings.Join(cycle.Elements, ", "))
+		}
+	}
+
+	return len(analysis.Unreachable) == 0
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(runLint(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	botToken := flag.String("bot", "", "Telegram bot token")
+	botWebhook := flag.String("bot-webhook", "", "Receive Telegram updates through a webhook at address and path (e.g. :8443/telegram)")
+	botWebhookURL := flag.String("bot-webhook-url", "", "Public URL of the webhook to register with Telegram")
+	botWebhookSecret := flag.String("bot-webhook-secret", "", "Secret token Telegram sends with webhook updates (random if -bot-webhook-url is set)")
+	devMode := flag.Bool("dev", false, "Enable developer mode")
+	apiMode := flag.String("api", "", "Start API server on specified port (e.g. :8080)")
+	adminAddr := flag.String("admin", "", "Serve the admin health endpoint on specified port (e.g. :9090)")
+	storeKind := flag.String("store", "file", "Progress store backend (file or bolt)")
+	validate := flag.Bool("validate", false, "Analyze recipe reachability and exit")
+	hintCooldown := flag.Duration("hint-cooldown", 30*time.Second, "Minimum time between hints for a player")
+	seed := flag.Uint64("seed", 0, "Seed for random recipe outcomes in the terminal game (0 picks one at random)")
+	var packPaths stringList
+	flag.Var(&packPaths, "pack", "Load a content pack directory or zip (repeatable)")
+	flag.Parse()
+
+	content, err := loadContent(*devMode, packPaths)
+	if err != nil {
+		fmt.Printf("Failed to load game state: %v\n", err)
+		return
+	}
+
+	if *validate {
+		if !printValidation(content) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	configDir, err := getConfigDir()
+	if err != nil {
+		fmt.Printf("Failed to load game state: %v\n", err)
+		return
+	}
+
+	store, err := openStore(*storeKind, configDir)
+	if err != nil {
+		fmt.Printf("Failed to open progress store: %v\n", err)
+		return
+	}
+	if closer, ok := store.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	if *apiMode != "" || *botToken != "" || *adminAddr != "" {
+		d := &daemon{
+			content:   content,
+			store:     store,
+			apiAddr:   *apiMode,
+			adminAddr: *adminAddr,
+		}
+
+		if *botToken != "" {
+			if *botWebhook != "" {
+				d.webhook, d.webhookURL, d.webhookSecret = *botWebhook, *botWebhookURL, *botWebhookSecret
+				if d.webhookSecret == "" {
+					if d.webhookURL == "" {
+						fmt.Println("-bot-webhook needs -bot-webhook-url or -bot-webhook-secret")
+						return
+					}
+					d.webhookSecret = newWebhookSecret()
+				}
+			}
+
+			d.bot, err = NewTelegramBot(*botToken, content, store, *hintCooldown)
+			if err != nil {
+				fmt.Printf("Failed to start Telegram Bot: %v\n", err)
+				return
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if err := d.run(ctx); err != nil {
+			log.Print(err)
+		}
+		return
+	}
+
+	gameState, err := craft.LoadGameState(content, store, localPlayer)
+	if err != nil {
+		fmt.Printf("Failed to load game state: %v\n", err)
+		return
+	}
+	gameState.Source = craft.SourceCLI
+	if *seed != 0 {
+		gameState.Rand = rand.New(rand.NewPCG(*seed, 0))
+	}
+
+	reload := func() (*craft.Content, error) {
+		return loadContent(true, packPaths)
+	}
+	runCLI(gameState, store, *hintCooldown, *devMode, reload, bufio.NewScanner(os.Stdin))
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref"`
+	Type                 string                    `json:"type"`
+	Required             []string                  `json:"required"`
+	Properties           map[string]*openAPISchema `json:"properties"`
+	Items                *openAPISchema            `json:"items"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties"`
+	Enum                 []string                  `json:"enum"`
+}
+
+type openAPIResponse struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Schema *openAPISchema `json:"schema"`
+	} `json:"content"`
+}
+
+type openAPIOperation struct {
+	Responses map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas   map[string]*openAPISchema   `json:"schemas"`
+		Responses map[string]*openAPIResponse `json:"responses"`
+	} `json:"components"`
+}
+
+func loadOpenAPI(t *testing.T) *openAPIDocument {
+	t.Helper()
+
+	var doc openAPIDocument
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("Failed to parse openapi.json: %v", err)
+	}
+	return &doc
+}
+
+func (doc *openAPIDocument) schema(s *openAPISchema) *openAPISchema {
+	if s != nil && s.Ref != "" {
+		return doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// responseSchema returns the schema documented for a response, or nil if
+// the status code is not documented at all.
+func (doc *openAPIDocument) responseSchema(t *testing.T, path, method string, status int) *openAPISchema {
+	t.Helper()
+
+	var op openAPIOperation
+	if err := json.Unmarshal(doc.Paths[path][strings.ToLower(method)], &op); err != nil {
+		t.Fatalf("%s %s is not documented", method, path)
+	}
+
+	response := op.Responses[strconv.Itoa(status)]
+	if response == nil {
+		return nil
+	}
+	if response.Ref != "" {
+		response = doc.Components.Responses[strings.TrimPrefix(response.Ref, "#/components/responses/")]
+	}
+	return doc.schema(response.Content["application/json"].Schema)
+}
+
+func (doc *openAPIDocument) validate(s *openAPISchema, value any, at string) error {
+	s = doc.schema(s)
+	if s == nil {
+		return fmt.Errorf("%s: unresolved schema", at)
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", at, value)
+		}
+		for _, name := range s.Required {
+			if _, exists := object[name]; !exists {
+				return fmt.Errorf("%s: missing required property %q", at, name)
+			}
+		}
+		for name, field := range object {
+			property := s.Properties[name]
+			if property == nil {
+				property = s.AdditionalProperties
+			}
+			if property == nil {
+				if s.Properties == nil {
+					continue
+				}
+				return fmt.Errorf("%s: undocumented property %q", at, name)
+			}
+			if err := doc.validate(property, field, at+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", at, value)
+		}
+		for i, item := range array {
+			if err := doc.validate(s.Items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string, got %T", at, value)
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			return fmt.Errorf("%s: %q is not one of %v", at, str, s.Enum)
+		}
+	case "integer":
+		if number, ok := value.(float64); !ok || number != float64(int64(number)) {
+			return fmt.Errorf("%s: expected integer, got %v", at, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: expected number, got %T", at, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", at, value)
+		}
+	}
+	return nil
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	server := newTestAPI(t)
+
+	resp, err := http.Get(server.URL + "/openapi.json")
+	if err != nil {
+		t.Fatalf("GET /openapi.json: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(body, openAPISpec) {
+		t.Errorf("GET /openapi.json: status %d, want the embedded document", resp.StatusCode)
+	}
+}
+
+func TestOpenAPISchemasMatchStructs(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	types := map[string]reflect.Type{
+		"CombineRequest":      reflect.TypeFor[CombineRequest](),
+		"CombineResponse":     reflect.TypeFor[CombineResponse](),
+		"ElementResponse":     reflect.TypeFor[ElementResponse](),
+		"PlayerResponse":      reflect.TypeFor[PlayerResponse](),
+		"CategoryResponse":    reflect.TypeFor[CategoryResponse](),
+		"StepResponse":        reflect.TypeFor[StepResponse](),
+		"PathResponse":        reflect.TypeFor[PathResponse](),
+		"ErrorResponse":       reflect.TypeFor[ErrorResponse](),
+		"TriedResponse":       reflect.TypeFor[TriedResponse](),
+		"AchievementResponse": reflect.TypeFor[AchievementResponse](),
+		"RaceRequest":         reflect.TypeFor[RaceRequest](),
+		"JoinRaceRequest":     reflect.TypeFor[JoinRaceRequest](),
+		"JoinRaceResponse":    reflect.TypeFor[JoinRaceResponse](),
+		"RaceCombineRequest":  reflect.TypeFor[RaceCombineRequest](),
+		"RacerResponse":       reflect.TypeFor[RacerResponse](),
+		"RaceResponse":        reflect.TypeFor[RaceResponse](),
+		"RaceCombineResponse": reflect.TypeFor[RaceCombineResponse](),
+	}
+
+	kinds := map[reflect.Kind]string{
+		reflect.String:  "string",
+		reflect.Bool:    "boolean",
+		reflect.Int:     "integer",
+		reflect.Uint64:  "integer",
+		reflect.Float64: "number",
+		reflect.Slice:   "array",
+		reflect.Struct:  "object",
+	}
+
+	for name, typ := range types {
+		schema := doc.Components.Schemas[name]
+		if schema == nil {
+			t.Errorf("Schema %s is missing", name)
+			continue
+		}
+
+		documented := make(map[string]bool)
+		for property := range schema.Properties {
+			documented[property] = true
+		}
+
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			tag, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+			property := schema.Properties[tag]
+			if property == nil {
+				t.Errorf("%s.%s (%s) is not documented", name, field.Name, tag)
+				continue
+			}
+			delete(documented, tag)
+
+			kind := field.Type.Kind()
+			if kind == reflect.Pointer {
+				kind = field.Type.Elem().Kind()
+			}
+			if want := kinds[kind]; doc.schema(property).Type != want {
+				t.Errorf("%s.%s is documented as %s, want %s", name, tag, doc.schema(property).Type, want)
+			}
+
+			required := slices.Contains(schema.Required, tag)
+			if omitempty := strings.Contains(options, "omitempty"); required == omitempty {
+				t.Errorf("%s.%s: required = %v but omitempty = %v", name, tag, required, omitempty)
+			}
+		}
+
+		for property := range documented {
+			t.Errorf("%s.%s is documented but not in the struct", name, property)
+		}
+	}
+}
+
+func TestAPIResponsesMatchOpenAPI(t *testing.T) {
+	doc := loadOpenAPI(t)
+	server := newTestAPI(t)
+
+	call := func(method, path, route string, body string) any {
+		t.Helper()
+
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+
+		schema := doc.responseSchema(t, route, method, resp.StatusCode)
+		if schema == nil {
+			t.Fatalf("%s %s returned undocumented status %d", method, path, resp.StatusCode)
+		}
+
+		var value any
+		if err := json.NewDecoder(resp.Body).Decode(&value); err != nil {
+			t.Fatalf("%s %s: invalid JSON: %v", method, path, err)
+		}
+		if err := doc.validate(schema, value, "response"); err != nil {
+			t.Errorf("%s %s: %v", method, path, err)
+		}
+		return value
+	}
+
+	player, _ := call("POST", "/players", "/players", "").(map[string]any)
+	id, _ := player["id"].(string)
+	base := "/players/" + id
+
+	call("GET", base, "/players/{id}", "")
+	call("GET", "/players/bad$id", "/players/{id}", "")
+	call("GET", "/players/never-created", "/players/{id}", "")
+	call("POST", base+"/combine", "/players/{id}/combine", `{"element_one": "water", "element_two": "fire"}`)
+	call("POST", base+"/combine", "/players/{id}/combine", `{"element_one": "lava", "element_two": "fire"}`)
+	call("GET", base+"/tried", "/players/{id}/tried", "")
+	call("GET", base+"/save", "/players/{id}/save", "")
+	call("PUT", base+"/save", "/players/{id}/save", `["water", "fire", "steam"]`)
+	call("PUT", base+"/save", "/players/{id}/save", `["ghost"]`)
+	call("DELETE", base+"/progress", "/players/{id}/progress", "")
+	call("GET", "/combine?element-one=water&element-two=fire", "/combine", "")
+	call("GET", "/categories", "/categories", "")
+	call("GET", "/path?target=solar-system", "/path", "")
+	call("GET", "/path?target=steam&player="+id, "/path", "")
+	call("GET", "/path?target=nothing", "/path", "")
+
+	race, _ := call("POST", "/races", "/races", `{"target": "steam"}`).(map[string]any)
+	raceID, _ := race["id"].(string)
+	racePath := "/races/" + raceID
+
+	call("POST", "/races", "/races", `{"target": "water"}`)
+	call("POST", racePath+"/racers", "/races/{race}/racers", `{"player": "never-created"}`)
+	joined, _ := call("POST", racePath+"/racers", "/races/{race}/racers", `{"player": "`+id+`", "name": "Ada"}`).(map[string]any)
+	token, _ := joined["token"].(string)
+	call("POST", racePath+"/combine", "/races/{race}/combine", `{"token": "`+token+`", "elements": ["water", "fire"]}`)
+	call("POST", racePath+"/start", "/races/{race}/start", "")
+	call("POST", racePath+"/start", "/races/{race}/start", "")
+	late, _ := call("POST", "/players", "/players", "").(map[string]any)
+	lateID, _ := late["id"].(string)
+	call("POST", racePath+"/racers", "/races/{race}/racers", `{"player": "`+lateID+`"}`)
+	call("POST", racePath+"/combine", "/races/{race}/combine", `{"token": "late", "elements": ["water", "fire"]}`)
+	call("POST", racePath+"/combine", "/races/{race}/combine", `{"token": "`+token+`", "elements": ["water", "fire"]}`)
+	call("GET", racePath, "/races/{race}", "")
+	call("GET", "/races/NOPE0", "/races/{race}", "")
+}
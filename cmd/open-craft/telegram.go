@@ -0,0 +1,568 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/tracepanic/open-craft/craft"
+)
+
+// Messenger is the part of the Telegram Bot API used by TelegramBot. It is
+// implemented by *tgbotapi.BotAPI.
+type Messenger interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	GetFileDirectURL(fileID string) (string, error)
+	MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error)
+	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
+	StopReceivingUpdates()
+}
+
+// TelegramBot serves the game over the Telegram Bot API. Updates from
+// different chats are handled concurrently, while the updates of each chat
+// are handled one at a time in the order they arrive.
+type TelegramBot struct {
+	bot          Messenger
+	content      *craft.Content
+	store        craft.ProgressStore
+	sessions     *craft.Sessions
+	hintCooldown time.Duration
+	httpClient   *http.Client
+	races        *craft.Races
+	// username is the bot's own username, which commands may be addressed
+	// to in group chats as /command@username.
+	username string
+
+	// mu guards userStates, chats, raceChats and stopped.
+	mu         sync.Mutex
+	userStates map[stateKey]UserState
+	chats      map[int64]*chatQueue
+	// raceChats holds the chats to announce each race in.
+	raceChats map[string][]int64
+	stopped   bool
+	workers   sync.WaitGroup
+}
+
+// chatQueue holds the updates of a chat waiting to be handled. While
+// running is set a worker goroutine is draining the queue.
+type chatQueue struct {
+	updates []tgbotapi.Update
+	running bool
+}
+
+type UserState struct {
+	waitingForFirstElement  bool
+	waitingForSecondElement bool
+	firstElement            string
+
+	// pendingImport is a save file waiting for the player to confirm
+	// the import.
+	pendingImport *craft.Progress
+}
+
+func telegramPlayer(userID int64) string {
+	return fmt.Sprintf("telegram/%d", userID)
+}
+
+func NewTelegramBot(token string, content *craft.Content, store craft.ProgressStore, hintCooldown time.Duration) (*TelegramBot, error) {
+	bot, err := tgbotapi.NewBotAPI(token)
+	if err != nil {
+		return nil, err
+	}
+	tb := newTelegramBot(bot, content, store, hintCooldown)
+	tb.username = bot.Self.UserName
+	return tb, nil
+}
+
+func newTelegramBot(bot Messenger, content *craft.Content, store craft.ProgressStore, hintCooldown time.Duration) *TelegramBot {
+	return &TelegramBot{
+		bot:          bot,
+		content:      content,
+		store:        store,
+		sessions:     craft.NewSessions(content, store, craft.SourceTelegram),
+		hintCooldown: hintCooldown,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		races:        craft.NewRaces(content),
+		userStates:   make(map[stateKey]UserState),
+		chats:        make(map[int64]*chatQueue),
+		raceChats:    make(map[string][]int64),
+	}
+}
+
+// getUserGameState returns the shared game state of a user, telling them
+// if their save had to be restored from a backup. Callers must hold its lock
+// while using it.
+func (tb *TelegramBot) getUserGameState(userID int64) (*craft.GameState, error) {
+	gameState, err := tb.sessions.Get(telegramPlayer(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	gameState.Lock()
+	restored := gameState.Restored
+	gameState.Restored = nil
+	gameState.Unlock()
+
+	if restored != nil {
+		tb.reportRestored(userID, restored)
+	}
+	return gameState, nil
+}
+
+func (tb *TelegramBot) reportRestored(chatID int64, restored error) {
+	log.Printf("Progress of chat %d: %v", chatID, restored)
+	msg := tgbotapi.NewMessage(chatID, "⚠️ Your save was damaged, so your progress has been restored from the last good backup.")
+	tb.bot.Send(msg)
+}
+
+func (tb *TelegramBot) userState(key stateKey) (UserState, bool) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	state, exists := tb.userStates[key]
+	return state, exists
+}
+
+func (tb *TelegramBot) setUserState(key stateKey, state UserState) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.userStates[key] = state
+}
+
+func (tb *TelegramBot) clearUserState(key stateKey) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	delete(tb.userStates, key)
+}
+
+func (tb *TelegramBot) sendMainMenu(chatID int64) {
+	keyboard := tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("🔮 Combine Elements"),
+			tgbotapi.NewKeyboardButton("📚 Discovered Elements"),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("💡 Show Hints"),
+			tgbotapi.NewKeyboardButton("📥 Download Save"),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, "Choose an option:")
+	msg.ReplyMarkup = keyboard
+	tb.bot.Send(msg)
+}
+
+func (tb *TelegramBot) sendDiscoveredElements(chatID int64) {
+	var keyboard [][]tgbotapi.KeyboardButton
+	var row []tgbotapi.KeyboardButton
+
+	for _, category := range tb.content.Categories {
+		row = append(row, tgbotapi.NewKeyboardButton(category.Label()))
+		if len(row) == 2 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		keyboard = append(keyboard, row)
+	}
+	keyboard = append(keyboard, tgbotapi.NewKeyboardButtonRow(
+		tgbotapi.NewKeyboardButton("📋 Show All Discovered"),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, "Select a category to view discovered elements:")
+	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(keyboard...)
+	tb.bot.Send(msg)
+}
+
+func (tb *TelegramBot) categoryForLabel(label string) (craft.Category, bool) {
+	for _, category := range tb.content.Categories {
+		if category.Label() == label {
+			return category, true
+		}
+	}
+	return craft.Category{}, false
+}
+
+func (tb *TelegramBot) showElementsByCategory(chatID int64, category craft.Category) {
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error loading game state")
+		tb.bot.Send(msg)
+		return
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	var elements strings.Builder
+	elements.WriteString(fmt.Sprintf("%s Elements:\n\n", category.Label()))
+
+	discoveredInCategory := 0
+	for _, name := range gameState.Discovered {
+		if element, exists := gameState.Elements[name]; exists {
+			if element.Category == category.ID {
+				elements.WriteString(fmt.Sprintf("- %s\n", element.Name))
+				discoveredInCategory++
+			}
+		}
+	}
+
+	if discoveredInCategory == 0 {
+		elements.WriteString("No elements discovered in this category yet!")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, elements.String())
+	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("◀️ Back to Categories"),
+			tgbotapi.NewKeyboardButton("🏠 Main Menu"),
+		),
+	)
+	tb.bot.Send(msg)
+}
+
+func (tb *TelegramBot) sendHints(chatID int64) {
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error loading game state")
+		tb.bot.Send(msg)
+		return
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	msg := tgbotapi.NewMessage(chatID, requestHint(gameState, tb.hintCooldown))
+	tb.bot.Send(msg)
+}
+
+func (tb *TelegramBot) sendPath(chatID int64, target string) {
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error loading game state")
+		tb.bot.Send(msg)
+		return
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	target = craft.NormalizeElementName(target)
+	if target == "" {
+		msg := tgbotapi.NewMessage(chatID, "Usage: /path <element>")
+		tb.bot.Send(msg)
+		return
+	}
+
+	steps, err := gameState.ShortestPath(gameState.Discovered, target)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ %v", err))
+		tb.bot.Send(msg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🧭 Path to %s:\n\n%s",
+		gameState.Elements[target].Name, formatPath(gameState.Content, steps)))
+	tb.bot.Send(msg)
+}
+
+func (tb *TelegramBot) sendSaveFile(chatID int64) {
+	_, err := tb.store.Load(telegramPlayer(chatID))
+	if errors.Is(err, craft.ErrNotFound) {
+		msg := tgbotapi.NewMessage(chatID, "No save file found")
+		tb.bot.Send(msg)
+		return
+	}
+	if errors.Is(err, craft.ErrRestored) {
+		tb.reportRestored(chatID, err)
+	}
+
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error loading game state")
+		tb.bot.Send(msg)
+		return
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	data, err := craft.EncodeProgress(gameState.Progress)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error preparing save file")
+		tb.bot.Send(msg)
+		return
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: "progress.json", Bytes: data})
+	doc.Caption = fmt.Sprintf("Your save file containing %d discovered elements", len(gameState.Discovered))
+
+	if _, err := tb.bot.Send(doc); err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error sending save file")
+		tb.bot.Send(msg)
+		return
+	}
+}
+
+func (tb *TelegramBot) showAllDiscovered(chatID int64) {
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error loading game state")
+		tb.bot.Send(msg)
+		return
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	var elements strings.Builder
+	elements.WriteString(fmt.Sprintf("All Discovered Elements (%d total):\n\n", len(gameState.Discovered)))
+
+	for _, name := range gameState.SortedDiscovered() {
+		if element, exists := gameState.Elements[name]; exists {
+			category, _ := gameState.Category(element.Category)
+			elements.WriteString(fmt.Sprintf("- %s (%s)\n", element.Name, category.Name))
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, elements.String())
+	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("◀️ Back to Categories"),
+			tgbotapi.NewKeyboardButton("🏠 Main Menu"),
+		),
+	)
+	tb.bot.Send(msg)
+}
+
+func (tb *TelegramBot) handleFirstElement(s sender, element string) {
+	chatID := s.chat.ID
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error loading game state")
+		tb.bot.Send(msg)
+		return
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	element = craft.NormalizeElementName(element)
+	if !gameState.IsDiscovered(element) {
+		msg := tgbotapi.NewMessage(chatID, "You haven't discovered this element yet! Try another one.")
+		tb.bot.Send(msg)
+		return
+	}
+
+	tb.setUserState(s.key(), UserState{
+		waitingForSecondElement: true,
+		firstElement:            element,
+	})
+
+	text, keyboard := elementPicker(gameState, 2, 0, element)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	tb.bot.Send(msg)
+}
+
+func (tb *TelegramBot) handleSecondElement(s sender, firstElement, secondElement string) {
+	chatID := s.chat.ID
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error loading game state")
+		tb.bot.Send(msg)
+		return
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	secondElement = craft.NormalizeElementName(secondElement)
+	if !gameState.IsDiscovered(secondElement) {
+		msg := tgbotapi.NewMessage(chatID, "You haven't discovered this element yet! Try another one.")
+		tb.bot.Send(msg)
+		return
+	}
+
+	result := tb.combine(gameState, s, firstElement, secondElement)
+	if err := gameState.Save(); err != nil {
+		log.Printf("Failed to save progress of chat %d: %v", chatID, err)
+	}
+	msg := tgbotapi.NewMessage(chatID, s.credit(describeCombine(gameState.Content, result)))
+	tb.bot.Send(msg)
+
+	tb.clearUserState(s.key())
+	tb.sendMainMenu(chatID)
+}
+
+// Start handles updates until ctx is cancelled, then waits for the updates
+// already received to be handled and saves every player.
+func (tb *TelegramBot) Start(ctx context.Context) error {
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+
+	tb.registerCommands()
+	updates := tb.bot.GetUpdatesChan(u)
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			tb.bot.StopReceivingUpdates()
+			break loop
+		case update, ok := <-updates:
+			if !ok {
+				break loop
+			}
+			tb.dispatch(update)
+		}
+	}
+
+	return tb.stop()
+}
+
+// stop refuses further updates, waits for the queued ones to be handled and
+// saves every player.
+func (tb *TelegramBot) stop() error {
+	tb.mu.Lock()
+	tb.stopped = true
+	tb.mu.Unlock()
+
+	tb.workers.Wait()
+	return tb.sessions.SaveAll()
+}
+
+// dispatch queues an update for its chat, starting a worker for the chat if
+// none is running. It reports false if the bot has stopped.
+func (tb *TelegramBot) dispatch(update tgbotapi.Update) bool {
+	chat := update.FromChat()
+	if chat == nil {
+		return true
+	}
+
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.stopped {
+		return false
+	}
+
+	queue, exists := tb.chats[chat.ID]
+	if !exists {
+		queue = &chatQueue{}
+		tb.chats[chat.ID] = queue
+	}
+	queue.updates = append(queue.updates, update)
+
+	if !queue.running {
+		queue.running = true
+		tb.workers.Add(1)
+		go tb.work(chat.ID, queue)
+	}
+	return true
+}
+
+// work handles the queued updates of a chat until the queue is empty.
+func (tb *TelegramBot) work(chatID int64, queue *chatQueue) {
+	defer tb.workers.Done()
+
+	for {
+		tb.mu.Lock()
+		if len(queue.updates) == 0 {
+			queue.running = false
+			delete(tb.chats, chatID)
+			tb.mu.Unlock()
+			return
+		}
+		update := queue.updates[0]
+		queue.updates = queue.updates[1:]
+		tb.mu.Unlock()
+
+		tb.handleUpdate(update)
+	}
+}
+
+func (tb *TelegramBot) handleUpdate(update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		tb.handleCallback(update.CallbackQuery)
+		return
+	}
+	if update.Message == nil {
+		return
+	}
+
+	s := sender{chat: update.Message.Chat, user: update.Message.From}
+	if update.Message.Document != nil {
+		// Files shared in a group are not meant for the bot.
+		if !s.shared() {
+			tb.handleSaveUpload(s, update.Message)
+		}
+		return
+	}
+	if update.Message.IsCommand() {
+		tb.handleCommand(s, update.Message)
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	msg := update.Message.Text
+
+	switch msg {
+	case "🔮 Combine Elements":
+		tb.setUserState(s.key(), UserState{waitingForFirstElement: true})
+		tb.sendElementPicker(chatID)
+	case "📚 Discovered Elements":
+		tb.sendDiscoveredElements(chatID)
+	case "💡 Show Hints":
+		tb.sendHints(chatID)
+	case "📥 Download Save":
+		tb.sendSaveFile(chatID)
+	case "📋 Show All Discovered":
+		tb.showAllDiscovered(chatID)
+	case "◀️ Back to Categories":
+		tb.sendDiscoveredElements(chatID)
+	case "🏠 Main Menu":
+		tb.sendMainMenu(chatID)
+	default:
+		if category, ok := tb.categoryForLabel(msg); ok {
+			tb.showElementsByCategory(chatID, category)
+		} else if state, exists := tb.userState(s.key()); exists {
+			if state.waitingForFirstElement {
+				tb.handleFirstElement(s, msg)
+			} else if state.waitingForSecondElement {
+				tb.handleSecondElement(s, state.firstElement, msg)
+			}
+		}
+	}
+}
+
+// handleCallback handles a tap on an inline keyboard button. Callback data
+// starts with an action followed by its arguments, separated by colons.
+func (tb *TelegramBot) handleCallback(query *tgbotapi.CallbackQuery) {
+	var answer string
+	defer func() {
+		tb.bot.Request(tgbotapi.NewCallback(query.ID, answer))
+	}()
+
+	if query.Message == nil {
+		return
+	}
+
+	s := sender{chat: query.Message.Chat, user: query.From}
+	action, args, _ := strings.Cut(query.Data, ":")
+	switch action {
+	case "page", "pick":
+		answer = tb.handlePickerCallback(s, query.Message, action, args)
+	case "reset":
+		answer = tb.handleResetCallback(s, query.Message, args)
+	case "import":
+		answer = tb.handleImportCallback(s, query.Message, args)
+	case "race":
+		answer = tb.handleRaceCallback(s, query.Message, args)
+	default:
+		answer = "Unknown button"
+	}
+}
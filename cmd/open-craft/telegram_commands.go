@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// telegramCommands are registered with setMyCommands so that clients offer
+// them in the command menu.
+var telegramCommands = []tgbotapi.BotCommand{
+	{Command: "combine", Description: "Combine elements, e.g. /combine water fire"},
+	{Command: "inventory", Description: "List every discovered element"},
+	{Command: "category", Description: "List discovered elements of a category"},
+	{Command: "progress", Description: "Show your progress"},
+	{Command: "hint", Description: "Get a hint"},
+	{Command: "path", Description: "Show how to make an element"},
+	{Command: "export", Description: "Download your save file"},
+	{Command: "import", Description: "Import a save file"},
+	{Command: "reset", Description: "Start over"},
+	{Command: "leaderboard", Description: "Show who discovered what in a group chat"},
+	{Command: "race", Description: "Race others to craft an element first"},
+}
+
+func (tb *TelegramBot) registerCommands() {
+	if _, err := tb.bot.Request(tgbotapi.NewSetMyCommands(telegramCommands...)); err != nil {
+		log.Printf("Failed to register bot commands: %v", err)
+	}
+}
+
+// handleCommand runs a slash command. Commands work from any state and
+// cancel a combination in progress. Commands addressed to another bot with
+// /command@otherbot are ignored.
+func (tb *TelegramBot) handleCommand(s sender, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	args := message.CommandArguments()
+
+	if _, to, addressed := strings.Cut(message.CommandWithAt(), "@"); addressed && !strings.EqualFold(to, tb.username) {
+		return
+	}
+
+	switch message.Command() {
+	case "start":
+		tb.clearUserState(s.key())
+		welcome := "Welcome to Open Craft! 🌟\nCombine elements to discover new ones!"
+		if s.shared() {
+			welcome += "\nEveryone in this chat shares one discovery pool. See /leaderboard for who found what."
+		}
+		welcomeMsg := tgbotapi.NewMessage(chatID, welcome)
+		tb.bot.Send(welcomeMsg)
+		tb.sendMainMenu(chatID)
+	case "combine":
+		tb.clearUserState(s.key())
+		tb.combineCommand(s, args)
+	case "inventory":
+		tb.showAllDiscovered(chatID)
+	case "category":
+		tb.categoryCommand(chatID, args)
+	case "progress":
+		tb.sendProgress(chatID)
+	case "hint":
+		tb.sendHints(chatID)
+	case "path":
+		tb.sendPath(chatID, args)
+	case "export":
+		tb.sendSaveFile(chatID)
+	case "leaderboard":
+		tb.sendLeaderboard(s)
+	case "race":
+		tb.clearUserState(s.key())
+		tb.raceCommand(s, args)
+	case "import":
+		if tb.refuseShared(s) {
+			return
+		}
+		tb.importCommand(s, args)
+	case "reset":
+		if tb.refuseShared(s) {
+			return
+		}
+		tb.clearUserState(s.key())
+		msg := tgbotapi.NewMessage(chatID, "⚠️ This throws away every discovery and starts over. Are you sure?")
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑️ Start over", "reset:confirm"),
+			tgbotapi.NewInlineKeyboardButtonData("Cancel", "reset:cancel"),
+		))
+		tb.bot.Send(msg)
+	default:
+		msg := tgbotapi.NewMessage(chatID, "Unknown command. Type / to see the available commands.")
+		tb.bot.Send(msg)
+	}
+}
+
+// splitElements splits command arguments into element names. Names are
+// separated by "+" or "," if either is present, so that they may contain
+// spaces, and by spaces otherwise.
+func splitElements(args string) []string {
+	var names []string
+	if strings.ContainsAny(args, "+,") {
+		names = strings.FieldsFunc(args, func(r rune) bool { return r == '+' || r == ',' })
+	} else {
+		names = strings.Fields(args)
+	}
+
+	var elements []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			elements = append(elements, name)
+		}
+	}
+	return elements
+}
+
+func (tb *TelegramBot) combineCommand(s sender, args string) {
+	chatID := s.chat.ID
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error loading game state")
+		tb.bot.Send(msg)
+		return
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	names := splitElements(args)
+	if len(names) < 2 || len(names) > gameState.MaxInputs() {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Usage: /combine <element> <element> (up to %d elements)", gameState.MaxInputs()))
+		tb.bot.Send(msg)
+		return
+	}
+
+	var inputs, labels []string
+	for _, name := range names {
+		element, ok := gameState.MatchElement(name, gameState.Discovered)
+		if !ok {
+			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("You haven't discovered anything called %q yet!", name))
+			tb.bot.Send(msg)
+			return
+		}
+		inputs = append(inputs, element)
+		labels = append(labels, gameState.Elements[element].Name)
+	}
+
+	result := tb.combine(gameState, s, inputs...)
+	if err := gameState.Save(); err != nil {
+		log.Printf("Failed to save progress of chat %d: %v", chatID, err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, s.credit(fmt.Sprintf("%s\n\n%s",
+		strings.Join(labels, " + "), describeCombine(gameState.Content, result))))
+	tb.bot.Send(msg)
+}
+
+func (tb *TelegramBot) categoryCommand(chatID int64, args string) {
+	if strings.TrimSpace(args) == "" {
+		tb.sendDiscoveredElements(chatID)
+		return
+	}
+
+	category, ok := tb.content.MatchCategory(args)
+	if !ok {
+		var ids []string
+		for _, category := range tb.content.Categories {
+			ids = append(ids, category.ID)
+		}
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Unknown category %q. Try one of: %s", strings.TrimSpace(args), strings.Join(ids, ", ")))
+		tb.bot.Send(msg)
+		return
+	}
+	tb.showElementsByCategory(chatID, category)
+}
+
+func (tb *TelegramBot) sendProgress(chatID int64) {
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error loading game state")
+		tb.bot.Send(msg)
+		return
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	discovered := make(map[string]int)
+	total := make(map[string]int)
+	for _, element := range gameState.Elements {
+		total[element.Category]++
+	}
+	for _, name := range gameState.Discovered {
+		discovered[gameState.Elements[name].Category]++
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("📊 Discovered Elements: %d/%d\n", len(gameState.Discovered), len(gameState.Elements)))
+	if len(gameState.Achievements) > 0 {
+		text.WriteString(fmt.Sprintf("🏆 Achievements: %d/%d\n", len(gameState.Unlocked), len(gameState.Achievements)))
+	}
+	text.WriteString(fmt.Sprintf("🔮 Attempts: %d (%d failed)\n\n", gameState.Attempts, gameState.FailedAttempts))
+	for _, category := range gameState.Categories {
+		text.WriteString(fmt.Sprintf("%s: %d/%d\n", category.Label(), discovered[category.ID], total[category.ID]))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	tb.bot.Send(msg)
+}
+
+// importCommand offers to import a save pasted after the command.
+func (tb *TelegramBot) importCommand(s sender, args string) {
+	if strings.TrimSpace(args) == "" {
+		msg := tgbotapi.NewMessage(s.chat.ID, "Send me your save file as a document, or paste its contents after /import.")
+		tb.bot.Send(msg)
+		return
+	}
+	tb.offerImport(s, []byte(args))
+}
+
+// handleResetCallback answers the buttons offered by /reset.
+func (tb *TelegramBot) handleResetCallback(s sender, message *tgbotapi.Message, choice string) string {
+	chatID := message.Chat.ID
+
+	if choice != "confirm" {
+		tb.bot.Send(tgbotapi.NewEditMessageText(chatID, message.MessageID, "Reset cancelled."))
+		return ""
+	}
+
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		return "Error loading game state"
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	if err := gameState.Reset(); err != nil {
+		log.Printf("Failed to reset progress of chat %d: %v", chatID, err)
+		return "Error resetting progress"
+	}
+
+	tb.clearUserState(s.key())
+	tb.bot.Send(tgbotapi.NewEditMessageText(chatID, message.MessageID, "🗑️ Progress reset. You are back to the starting elements."))
+	return ""
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/tracepanic/open-craft/craft"
+)
+
+// sender is where an update came from: the chat, and the user in it. In a
+// group chat every member shares the chat's discovery pool, while each
+// member has their own pending state.
+type sender struct {
+	chat *tgbotapi.Chat
+	user *tgbotapi.User
+}
+
+// stateKey identifies the pending state of one user in one chat.
+type stateKey struct {
+	chatID int64
+	userID int64
+}
+
+func (s sender) key() stateKey {
+	if s.user == nil {
+		return stateKey{chatID: s.chat.ID, userID: s.chat.ID}
+	}
+	return stateKey{chatID: s.chat.ID, userID: s.user.ID}
+}
+
+// shared reports whether the chat is a group playing cooperatively.
+func (s sender) shared() bool {
+	return s.chat.IsGroup() || s.chat.IsSuperGroup()
+}
+
+func (s sender) name() string {
+	if s.user == nil {
+		return "Someone"
+	}
+	if name := strings.TrimSpace(s.user.FirstName + " " + s.user.LastName); name != "" {
+		return name
+	}
+	if s.user.UserName != "" {
+		return "@" + s.user.UserName
+	}
+	return strconv.FormatInt(s.user.ID, 10)
+}
+
+// credit prefixes text with the sender's name in group chats, so that
+// everyone can see who made a combination.
+func (s sender) credit(text string) string {
+	if !s.shared() {
+		return text
+	}
+	return fmt.Sprintf("👤 %s: %s", s.name(), text)
+}
+
+// combine combines inputs, crediting the sender in group chats. Callers must
+// hold the game state lock.
+func (tb *TelegramBot) combine(gameState *craft.GameState, s sender, inputs ...string) craft.CombineResult {
+	if !s.shared() || s.user == nil {
+		return gameState.Combine(inputs...)
+	}
+	return gameState.CombineAs(strconv.FormatInt(s.user.ID, 10), s.name(), inputs...)
+}
+
+// refuseShared tells a group chat that its shared discovery pool can't be
+// replaced, and reports whether it did.
+func (tb *TelegramBot) refuseShared(s sender) bool {
+	if !s.shared() {
+		return false
+	}
+	msg := tgbotapi.NewMessage(s.chat.ID, "Everyone in this chat shares one discovery pool, so it can't be imported or reset here.")
+	tb.bot.Send(msg)
+	return true
+}
+
+func (tb *TelegramBot) sendLeaderboard(s sender) {
+	chatID := s.chat.ID
+	if !s.shared() {
+		msg := tgbotapi.NewMessage(chatID, "The leaderboard is for group chats, where everyone discovers elements together.")
+		tb.bot.Send(msg)
+		return
+	}
+
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error loading game state")
+		tb.bot.Send(msg)
+		return
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	members := gameState.Leaderboard()
+	if len(members) == 0 {
+		msg := tgbotapi.NewMessage(chatID, "Nobody has combined anything yet!")
+		tb.bot.Send(msg)
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🏆 Leaderboard (%d elements discovered together):\n\n", len(gameState.Discovered)))
+	medals := []string{"🥇", "🥈", "🥉"}
+	for i, member := range members {
+		rank := fmt.Sprintf("%d.", i+1)
+		if i < len(medals) {
+			rank = medals[i]
+		}
+		text.WriteString(fmt.Sprintf("%s %s: %d discoveries, %d attempts\n", rank, member.Name, member.Discoveries, member.Attempts))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	tb.bot.Send(msg)
+}
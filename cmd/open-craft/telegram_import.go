@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/tracepanic/open-craft/craft"
+)
+
+// maxSaveSize is the largest save file the bot downloads.
+const maxSaveSize = 1 << 20
+
+// handleSaveUpload downloads a save file sent as a document and offers to
+// import it.
+func (tb *TelegramBot) handleSaveUpload(s sender, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	document := message.Document
+
+	if document.FileSize > maxSaveSize {
+		msg := tgbotapi.NewMessage(chatID, "❌ That file is too large to be a save file.")
+		tb.bot.Send(msg)
+		return
+	}
+
+	data, err := tb.downloadFile(document.FileID)
+	if err != nil {
+		log.Printf("Failed to download save file of chat %d: %v", chatID, err)
+		msg := tgbotapi.NewMessage(chatID, "Error downloading save file")
+		tb.bot.Send(msg)
+		return
+	}
+
+	tb.offerImport(s, data)
+}
+
+func (tb *TelegramBot) downloadFile(fileID string) ([]byte, error) {
+	url, err := tb.bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := tb.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSaveSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSaveSize {
+		return nil, fmt.Errorf("save file is larger than %d bytes", maxSaveSize)
+	}
+	return data, nil
+}
+
+// offerImport validates a save file and shows how it differs from the
+// player's progress, asking for confirmation before replacing it.
+func (tb *TelegramBot) offerImport(s sender, data []byte) {
+	chatID := s.chat.ID
+
+	progress, err := craft.DecodeProgress(data)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Invalid save file: %v", err))
+		tb.bot.Send(msg)
+		return
+	}
+
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error loading game state")
+		tb.bot.Send(msg)
+		return
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	if err := gameState.ValidateProgress(progress); err != nil {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Invalid save file: %v", err))
+		tb.bot.Send(msg)
+		return
+	}
+
+	gained, lost := craft.DiffDiscovered(gameState.Progress, progress)
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("📤 Save file with %d discovered elements\n\n", len(progress.Discovered)))
+	if len(gained) == 0 && len(lost) == 0 {
+		text.WriteString("It has the same discovered elements as your current progress.\n")
+	}
+	if len(gained) > 0 {
+		text.WriteString(fmt.Sprintf("➕ Gained (%d): %s\n", len(gained), elementNames(gameState.Content, gained)))
+	}
+	if len(lost) > 0 {
+		text.WriteString(fmt.Sprintf("➖ Lost (%d): %s\n", len(lost), elementNames(gameState.Content, lost)))
+	}
+	text.WriteString("\nReplace your progress with this save?")
+
+	tb.setUserState(s.key(), UserState{pendingImport: progress})
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Replace", "import:confirm"),
+		tgbotapi.NewInlineKeyboardButtonData("Cancel", "import:cancel"),
+	))
+	tb.bot.Send(msg)
+}
+
+func elementNames(content *craft.Content, elements []string) string {
+	names := make([]string, len(elements))
+	for i, element := range elements {
+		names[i] = content.Elements[element].Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// handleImportCallback answers the buttons offered by offerImport.
+func (tb *TelegramBot) handleImportCallback(s sender, message *tgbotapi.Message, choice string) string {
+	chatID := message.Chat.ID
+
+	state, _ := tb.userState(s.key())
+	if state.pendingImport == nil {
+		return "This import has expired"
+	}
+	tb.clearUserState(s.key())
+
+	if choice != "confirm" {
+		tb.bot.Send(tgbotapi.NewEditMessageText(chatID, message.MessageID, "Import cancelled."))
+		return ""
+	}
+
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		return "Error loading game state"
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	if err := gameState.Replace(state.pendingImport); err != nil {
+		log.Printf("Failed to import progress of chat %d: %v", chatID, err)
+		return "Error saving progress"
+	}
+
+	tb.bot.Send(tgbotapi.NewEditMessageText(chatID, message.MessageID,
+		fmt.Sprintf("📤 Imported a save with %d discovered elements", len(gameState.Discovered))))
+	return ""
+}
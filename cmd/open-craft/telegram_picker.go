@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/tracepanic/open-craft/craft"
+)
+
+const (
+	pickerPageSize = 12
+	pickerColumns  = 3
+)
+
+// pickerPage is one page of the inline element picker. Each page holds
+// elements of a single category.
+type pickerPage struct {
+	category craft.Category
+	elements []string
+	part     int
+	parts    int
+}
+
+// pickerPages splits the discovered elements into pages by category, in
+// category order.
+func pickerPages(gameState *craft.GameState) []pickerPage {
+	byCategory := make(map[string][]string)
+	for _, name := range gameState.SortedDiscovered() {
+		category := gameState.Elements[name].Category
+		byCategory[category] = append(byCategory[category], name)
+	}
+
+	var pages []pickerPage
+	for _, category := range gameState.Categories {
+		chunks := slices.Collect(slices.Chunk(byCategory[category.ID], pickerPageSize))
+		for i, chunk := range chunks {
+			pages = append(pages, pickerPage{
+				category: category,
+				elements: chunk,
+				part:     i + 1,
+				parts:    len(chunks),
+			})
+		}
+	}
+	return pages
+}
+
+// elementPicker renders a page of the picker for choosing the first (stage
+// 1) or second (stage 2) element of a combination. Buttons carry callback
+// data of the form "pick:<stage>:<element>" and "page:<stage>:<page>", which
+// fits in Telegram's 64 bytes because element keys are at most
+// craft.MaxElementKeyLength long.
+func elementPicker(gameState *craft.GameState, stage, page int, firstElement string) (string, tgbotapi.InlineKeyboardMarkup) {
+	pages := pickerPages(gameState)
+	page = max(0, min(page, len(pages)-1))
+	current := pages[page]
+
+	var text strings.Builder
+	if stage == 1 {
+		text.WriteString("Pick the first element or type its name:\n\n")
+	} else {
+		text.WriteString(fmt.Sprintf("First element: %s\nPick the second element or type its name:\n\n", gameState.Elements[firstElement].Name))
+	}
+	text.WriteString(current.category.Label())
+	if current.parts > 1 {
+		text.WriteString(fmt.Sprintf(" (%d/%d)", current.part, current.parts))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for chunk := range slices.Chunk(current.elements, pickerColumns) {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, name := range chunk {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+				gameState.Elements[name].Name, fmt.Sprintf("pick:%d:%s", stage, name)))
+		}
+		rows = append(rows, row)
+	}
+
+	var navigation []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		navigation = append(navigation, tgbotapi.NewInlineKeyboardButtonData(
+			"◀️ "+pages[page-1].category.Label(), fmt.Sprintf("page:%d:%d", stage, page-1)))
+	}
+	if page < len(pages)-1 {
+		navigation = append(navigation, tgbotapi.NewInlineKeyboardButtonData(
+			pages[page+1].category.Label()+" ▶️", fmt.Sprintf("page:%d:%d", stage, page+1)))
+	}
+	if len(navigation) > 0 {
+		rows = append(rows, navigation)
+	}
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func (tb *TelegramBot) sendElementPicker(chatID int64) {
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Error loading game state")
+		tb.bot.Send(msg)
+		return
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	text, keyboard := elementPicker(gameState, 1, 0, "")
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	tb.bot.Send(msg)
+}
+
+// handlePickerCallback handles a tap on a button of the element picker and
+// returns the answer to show the user, if any.
+func (tb *TelegramBot) handlePickerCallback(s sender, message *tgbotapi.Message, action, args string) string {
+	chatID := message.Chat.ID
+	messageID := message.MessageID
+
+	stageArg, value, _ := strings.Cut(args, ":")
+	stage, err := strconv.Atoi(stageArg)
+	if err != nil {
+		return "Unknown button"
+	}
+
+	gameState, err := tb.getUserGameState(chatID)
+	if err != nil {
+		return "Error loading game state"
+	}
+	gameState.Lock()
+	defer gameState.Unlock()
+
+	state, _ := tb.userState(s.key())
+	if stage == 2 && !state.waitingForSecondElement {
+		return "This picker has expired"
+	}
+
+	if action == "page" {
+		page, _ := strconv.Atoi(value)
+		text, keyboard := elementPicker(gameState, stage, page, state.firstElement)
+		tb.bot.Send(tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard))
+		return ""
+	}
+
+	if !gameState.IsDiscovered(value) {
+		return "You haven't discovered this element yet!"
+	}
+
+	if stage == 1 {
+		tb.setUserState(s.key(), UserState{
+			waitingForSecondElement: true,
+			firstElement:            value,
+		})
+		text, keyboard := elementPicker(gameState, 2, 0, value)
+		tb.bot.Send(tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard))
+		return ""
+	}
+
+	result := tb.combine(gameState, s, state.firstElement, value)
+	if err := gameState.Save(); err != nil {
+		log.Printf("Failed to save progress of chat %d: %v", chatID, err)
+	}
+	tb.bot.Send(tgbotapi.NewEditMessageText(chatID, messageID, s.credit(fmt.Sprintf("%s + %s\n\n%s",
+		gameState.Elements[state.firstElement].Name,
+		gameState.Elements[value].Name,
+		describeCombine(gameState.Content, result)))))
+
+	tb.clearUserState(s.key())
+	tb.sendMainMenu(chatID)
+	return ""
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/tracepanic/open-craft/craft"
+)
+
+const raceUsage = "🏁 Race others to craft an element first!\n\n" +
+	"/race new [element] – open a lobby\n" +
+	"/race join CODE – join a lobby\n" +
+	"/race start – start the race you joined\n" +
+	"/race status – show the standings\n" +
+	"/race <element> <element> – combine during the race"
+
+// racer is the ID of the sender in races, which are played by people rather
+// than chats.
+func (s sender) racer() string {
+	return telegramPlayer(s.key().userID)
+}
+
+// raceCommand runs /race and its subcommands. Anything that is not a
+// subcommand is combined in the sender's current race.
+func (tb *TelegramBot) raceCommand(s sender, args string) {
+	chatID := s.chat.ID
+	subcommand, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+
+	switch strings.ToLower(subcommand) {
+	case "":
+		if race, err := tb.races.Current(s.racer()); err == nil {
+			tb.sendRaceStatus(chatID, race)
+			return
+		}
+		msg := tgbotapi.NewMessage(chatID, raceUsage)
+		tb.bot.Send(msg)
+	case "new":
+		tb.createRace(s, rest)
+	case "join":
+		tb.joinRace(s, strings.TrimSpace(rest))
+	case "start":
+		race, err := tb.races.Current(s.racer())
+		if err != nil {
+			tb.sendRaceError(chatID, err)
+			return
+		}
+		if err := tb.startRace(race); err != nil {
+			tb.sendRaceError(chatID, err)
+		}
+	case "status":
+		race, err := tb.races.Current(s.racer())
+		if err != nil {
+			tb.sendRaceError(chatID, err)
+			return
+		}
+		tb.sendRaceStatus(chatID, race)
+	default:
+		tb.raceCombine(s, args)
+	}
+}
+
+func raceErrorText(err error) string {
+	switch {
+	case errors.Is(err, craft.ErrRaceNotFound):
+		return "There is no race with that code."
+	case errors.Is(err, craft.ErrNotRacing):
+		return "You aren't in a race. Open one with /race new or join one with /race join CODE."
+	case errors.Is(err, craft.ErrRaceStarted):
+		return "That race has already started."
+	case errors.Is(err, craft.ErrRaceNotRunning):
+		return "That race isn't running. See /race status."
+	default:
+		return fmt.Sprintf("❌ %v", err)
+	}
+}
+
+func (tb *TelegramBot) sendRaceError(chatID int64, err error) {
+	msg := tgbotapi.NewMessage(chatID, raceErrorText(err))
+	tb.bot.Send(msg)
+}
+
+// followRace makes the bot announce the start and end of race in chatID.
+func (tb *TelegramBot) followRace(race *craft.Race, chatID int64) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if !slices.Contains(tb.raceChats[race.ID], chatID) {
+		tb.raceChats[race.ID] = append(tb.raceChats[race.ID], chatID)
+	}
+}
+
+// announce sends text to every chat following race.
+func (tb *TelegramBot) announce(race *craft.Race, text string) {
+	tb.mu.Lock()
+	chats := slices.Clone(tb.raceChats[race.ID])
+	tb.mu.Unlock()
+
+	for _, chatID := range chats {
+		msg := tgbotapi.NewMessage(chatID, text)
+		tb.bot.Send(msg)
+	}
+}
+
+func (tb *TelegramBot) createRace(s sender, args string) {
+	chatID := s.chat.ID
+
+	var target string
+	if name := strings.TrimSpace(args); name != "" {
+		var ok bool
+		target, ok = tb.content.MatchElement(name, slices.Sorted(maps.Keys(tb.content.Elements)))
+		if !ok {
+			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("There is no element called %q.", name))
+			tb.bot.Send(msg)
+			return
+		}
+	}
+
+	race, err := tb.races.Create(target, 0, craft.DefaultRaceLimit)
+	if err != nil {
+		tb.sendRaceError(chatID, err)
+		return
+	}
+	if _, _, err := tb.races.Join(race.ID, s.racer(), s.name()); err != nil {
+		tb.sendRaceError(chatID, err)
+		return
+	}
+	tb.followRace(race, chatID)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(
+		"🏁 Race %s: the first to craft %s wins!\nSeed %d · %s time limit\n\nOthers can join with /race join %s. Start with /race start when everyone is in.",
+		race.ID, tb.content.Elements[race.Target].Name, race.Seed, formatDuration(race.Limit), race.ID))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🙋 Join", "race:join:"+race.ID),
+		tgbotapi.NewInlineKeyboardButtonData("🏁 Start", "race:start:"+race.ID),
+	))
+	tb.bot.Send(msg)
+}
+
+func (tb *TelegramBot) joinRace(s sender, id string) {
+	chatID := s.chat.ID
+	if id == "" {
+		msg := tgbotapi.NewMessage(chatID, "Usage: /race join CODE")
+		tb.bot.Send(msg)
+		return
+	}
+
+	race, _, err := tb.races.Join(id, s.racer(), s.name())
+	if err != nil {
+		tb.sendRaceError(chatID, err)
+		return
+	}
+	tb.followRace(race, chatID)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🙋 %s joined race %s (%d racers).",
+		s.name(), race.ID, len(race.Status(time.Now()).Standings)))
+	tb.bot.Send(msg)
+}
+
+func (tb *TelegramBot) startRace(race *craft.Race) error {
+	if err := race.Start(time.Now()); err != nil {
+		return err
+	}
+
+	tb.announce(race, fmt.Sprintf("🏁 Race %s has started! Be the first to craft %s with /race <element> <element>. You have %s.",
+		race.ID, tb.content.Elements[race.Target].Name, formatDuration(race.Limit)))
+	return nil
+}
+
+func (tb *TelegramBot) sendRaceStatus(chatID int64, race *craft.Race) {
+	msg := tgbotapi.NewMessage(chatID, describeRace(tb.content, race.Status(time.Now())))
+	tb.bot.Send(msg)
+}
+
+// describeRace shows the state of a race and its standings.
+func describeRace(content *craft.Content, status craft.RaceStatus) string {
+	var text strings.Builder
+	target := content.Elements[status.Target].Name
+
+	switch status.State {
+	case craft.RaceLobby:
+		text.WriteString(fmt.Sprintf("🏁 Race %s to craft %s is waiting to start.\n\n", status.ID, target))
+	case craft.RaceRunning:
+		text.WriteString(fmt.Sprintf("🏁 Race %s to craft %s: %s left.\n\n", status.ID, target, formatDuration(status.Remaining())))
+	case craft.RaceFinished:
+		text.WriteString(fmt.Sprintf("🏆 Race %s to craft %s is over.\n\n", status.ID, target))
+	case craft.RaceExpired:
+		text.WriteString(fmt.Sprintf("⏰ Race %s to craft %s ran out of time.\n\n", status.ID, target))
+	}
+
+	for i, standing := range status.Standings {
+		text.WriteString(fmt.Sprintf("%d. %s: ", i+1, standing.Name))
+		if standing.Finished {
+			text.WriteString(fmt.Sprintf("crafted it in %s, ", formatDuration(standing.Time)))
+		} else if status.State != craft.RaceLobby {
+			text.WriteString(fmt.Sprintf("%d elements, ", standing.Discovered))
+		}
+		text.WriteString(fmt.Sprintf("%d attempts\n", standing.Attempts))
+	}
+	return text.String()
+}
+
+func (tb *TelegramBot) raceCombine(s sender, args string) {
+	chatID := s.chat.ID
+
+	race, err := tb.races.Current(s.racer())
+	if err != nil {
+		tb.sendRaceError(chatID, err)
+		return
+	}
+	discovered, err := race.Discovered(s.racer())
+	if err != nil {
+		tb.sendRaceError(chatID, err)
+		return
+	}
+
+	names := splitElements(args)
+	if len(names) < 2 || len(names) > tb.content.MaxInputs() {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Usage: /race <element> <element> (up to %d elements)", tb.content.MaxInputs()))
+		tb.bot.Send(msg)
+		return
+	}
+
+	var inputs, labels []string
+	for _, name := range names {
+		element, ok := tb.content.MatchElement(name, discovered)
+		if !ok {
+			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("You haven't discovered anything called %q in this race yet!", name))
+			tb.bot.Send(msg)
+			return
+		}
+		inputs = append(inputs, element)
+		labels = append(labels, tb.content.Elements[element].Name)
+	}
+
+	result, err := race.Combine(s.racer(), time.Now(), inputs...)
+	if err != nil {
+		tb.sendRaceError(chatID, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, s.credit(fmt.Sprintf("%s\n\n%s",
+		strings.Join(labels, " + "), describeCombine(tb.content, result))))
+	tb.bot.Send(msg)
+
+	if status := race.Status(time.Now()); status.Winner == s.racer() {
+		winner := status.Standings[0]
+		tb.announce(race, fmt.Sprintf("🏆 %s crafted %s in %s with %d attempts and wins race %s!\n\n%s",
+			winner.Name, tb.content.Elements[race.Target].Name, formatDuration(winner.Time), winner.Attempts, race.ID,
+			describeRace(tb.content, status)))
+	}
+}
+
+// handleRaceCallback answers the buttons offered by /race new.
+func (tb *TelegramBot) handleRaceCallback(s sender, message *tgbotapi.Message, args string) string {
+	action, id, _ := strings.Cut(args, ":")
+
+	switch action {
+	case "join":
+		tb.joinRace(s, id)
+		return ""
+	case "start":
+		race, err := tb.races.Get(id)
+		if err != nil {
+			return raceErrorText(err)
+		}
+		if _, err := race.Discovered(s.racer()); err != nil {
+			return "Only racers can start the race"
+		}
+		if err := tb.startRace(race); err != nil {
+			return raceErrorText(err)
+		}
+		return ""
+	default:
+		return "Unknown button"
+	}
+}
@@ -0,0 +1,909 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/tracepanic/open-craft/craft"
+	"github.com/tracepanic/open-craft/data"
+)
+
+// sentRequest is a Bot API call made by the bot, other than getMe and
+// getUpdates.
+type sentRequest struct {
+	Method string
+	ChatID int64
+	Text   string
+	Form   url.Values
+}
+
+// fakeBotAPI is an httptest server that speaks enough of the Telegram Bot
+// API to run a TelegramBot against. Updates pushed to it are served to
+// getUpdates, and every other call is recorded.
+type fakeBotAPI struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	updates []tgbotapi.Update
+	pushed  chan struct{}
+	sent    []sentRequest
+}
+
+func newFakeBotAPI(t *testing.T) *fakeBotAPI {
+	t.Helper()
+
+	fake := &fakeBotAPI{pushed: make(chan struct{}, 1)}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+func (f *fakeBotAPI) newBot(t *testing.T) *tgbotapi.BotAPI {
+	t.Helper()
+
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", f.server.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatalf("Failed to connect to fake Bot API: %v", err)
+	}
+	return bot
+}
+
+func (f *fakeBotAPI) reply(w http.ResponseWriter, result any) {
+	data, _ := json.Marshal(result)
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: data})
+}
+
+func (f *fakeBotAPI) serve(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	switch method {
+	case "getMe":
+		f.reply(w, tgbotapi.User{ID: 1, IsBot: true, FirstName: "Open Craft", UserName: "open_craft_bot"})
+	case "getUpdates":
+		offset, _ := strconv.Atoi(r.FormValue("offset"))
+		f.reply(w, f.waitForUpdates(r.Context(), offset))
+	default:
+		chatID, _ := strconv.ParseInt(r.FormValue("chat_id"), 10, 64)
+
+		f.mu.Lock()
+		f.sent = append(f.sent, sentRequest{
+			Method: method,
+			ChatID: chatID,
+			Text:   r.FormValue("text"),
+			Form:   r.Form,
+		})
+		f.mu.Unlock()
+
+		f.reply(w, tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: chatID}})
+	}
+}
+
+// waitForUpdates returns the updates from offset on, waiting briefly for
+// more to be pushed if there are none yet.
+func (f *fakeBotAPI) waitForUpdates(ctx context.Context, offset int) []tgbotapi.Update {
+	timeout := time.After(50 * time.Millisecond)
+	for {
+		f.mu.Lock()
+		var updates []tgbotapi.Update
+		for _, update := range f.updates {
+			if update.UpdateID >= offset {
+				updates = append(updates, update)
+			}
+		}
+		f.mu.Unlock()
+
+		if len(updates) > 0 {
+			return updates
+		}
+
+		select {
+		case <-f.pushed:
+		case <-timeout:
+			return []tgbotapi.Update{}
+		case <-ctx.Done():
+			return []tgbotapi.Update{}
+		}
+	}
+}
+
+// textMessage returns a message from a user in their private chat, marking
+// a leading command the way Telegram does.
+func textMessage(id int, chatID int64, text string) *tgbotapi.Message {
+	message := &tgbotapi.Message{
+		MessageID: id,
+		From:      &tgbotapi.User{ID: chatID},
+		Chat:      &tgbotapi.Chat{ID: chatID, Type: "private"},
+		Text:      text,
+	}
+	if strings.HasPrefix(text, "/") {
+		command, _, _ := strings.Cut(text, " ")
+		message.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Length: len(command)}}
+	}
+	return message
+}
+
+// push queues a text message from a user in their private chat.
+func (f *fakeBotAPI) push(chatID int64, text string) {
+	f.mu.Lock()
+	id := len(f.updates) + 1
+	f.updates = append(f.updates, tgbotapi.Update{
+		UpdateID: id,
+		Message:  textMessage(id, chatID, text),
+	})
+	f.mu.Unlock()
+
+	select {
+	case f.pushed <- struct{}{}:
+	default:
+	}
+}
+
+// messages returns the text of every message sent to chatID.
+func (f *fakeBotAPI) messages(chatID int64) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var texts []string
+	for _, request := range f.sent {
+		if request.ChatID == chatID && request.Method == "sendMessage" {
+			texts = append(texts, request.Text)
+		}
+	}
+	return texts
+}
+
+func (f *fakeBotAPI) waitFor(t *testing.T, chatID int64, text string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, message := range f.messages(chatID) {
+			if strings.Contains(message, text) {
+				return
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Chat %d never received %q, got %q", chatID, text, f.messages(chatID))
+}
+
+func newTestTelegramBot(t *testing.T, fake *fakeBotAPI, store craft.ProgressStore) *TelegramBot {
+	t.Helper()
+
+	content, err := craft.LoadContent(data.FS)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+	return newTelegramBot(fake.newBot(t), content, store, 0)
+}
+
+func TestTelegramHandlesChatsConcurrently(t *testing.T) {
+	fake := newFakeBotAPI(t)
+	store := craft.NewMemoryStore()
+	bot := newTestTelegramBot(t, fake, store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- bot.Start(ctx) }()
+
+	const chats = 20
+	for _, text := range []string{"/start", "🔮 Combine Elements", "water", "fire", "💡 Show Hints"} {
+		for chatID := int64(1); chatID <= chats; chatID++ {
+			fake.push(chatID, text)
+		}
+	}
+
+	for chatID := int64(1); chatID <= chats; chatID++ {
+		fake.waitFor(t, chatID, "💡")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Start returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return after cancellation")
+	}
+
+	want := []string{
+		"Welcome to Open Craft! 🌟\nCombine elements to discover new ones!",
+		"Choose an option:",
+		"Pick the first element",
+		"First element: 💧 Water\nPick the second element",
+		"✨ You created: 💨 Steam!",
+		"Choose an option:",
+		"💡",
+	}
+	for chatID := int64(1); chatID <= chats; chatID++ {
+		messages := fake.messages(chatID)
+		if len(messages) != len(want) {
+			t.Errorf("Chat %d got %d messages, want %d: %q", chatID, len(messages), len(want), messages)
+			continue
+		}
+		for i, prefix := range want {
+			if !strings.HasPrefix(messages[i], prefix) {
+				t.Errorf("Chat %d message %d = %q, want prefix %q", chatID, i, messages[i], prefix)
+			}
+		}
+
+		progress, err := store.Load(telegramPlayer(chatID))
+		if err != nil {
+			t.Fatalf("Progress of chat %d was not saved: %v", chatID, err)
+		}
+		if progress.Hints.Used != 1 || len(progress.Discovered) != 5 {
+			t.Errorf("Saved progress of chat %d = %+v, want steam and one hint", chatID, progress)
+		}
+	}
+}
+
+func TestTelegramShutdownWithoutUpdates(t *testing.T) {
+	fake := newFakeBotAPI(t)
+	bot := newTestTelegramBot(t, fake, craft.NewMemoryStore())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := bot.Start(ctx); err != nil {
+		t.Fatalf("Start returned %v", err)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.sent) != 1 || fake.sent[0].Method != "setMyCommands" {
+		t.Errorf("Bot sent %+v without any updates, want only setMyCommands", fake.sent)
+	}
+}
+
+func TestTelegramWebhook(t *testing.T) {
+	fake := newFakeBotAPI(t)
+	store := craft.NewMemoryStore()
+	bot := newTestTelegramBot(t, fake, store)
+
+	webhook := httptest.NewServer(bot.WebhookHandler("s3cret"))
+	defer webhook.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- bot.StartWebhook(ctx, "https://example.com/telegram", "s3cret") }()
+
+	post := func(method, secret, body string) int {
+		t.Helper()
+
+		req, _ := http.NewRequest(method, webhook.URL, strings.NewReader(body))
+		if secret != "" {
+			req.Header.Set("X-Telegram-Bot-Api-Secret-Token", secret)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s webhook failed: %v", method, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// An update as recorded from the Bot API.
+	const update = `{
+		"update_id": 873150001,
+		"message": {
+			"message_id": 12,
+			"from": {"id": 7, "is_bot": false, "first_name": "Ada", "language_code": "en"},
+			"chat": {"id": 7, "first_name": "Ada", "type": "private"},
+			"date": 1760000000,
+			"text": "/combine water fire",
+			"entities": [{"offset": 0, "length": 8, "type": "bot_command"}]
+		}
+	}`
+
+	if status := post(http.MethodPost, "", update); status != http.StatusUnauthorized {
+		t.Errorf("Update without secret: status %d, want 401", status)
+	}
+	if status := post(http.MethodPost, "wrong", update); status != http.StatusUnauthorized {
+		t.Errorf("Update with wrong secret: status %d, want 401", status)
+	}
+	if status := post(http.MethodGet, "s3cret", ""); status != http.StatusMethodNotAllowed {
+		t.Errorf("GET: status %d, want 405", status)
+	}
+	if status := post(http.MethodPost, "s3cret", "{"); status != http.StatusBadRequest {
+		t.Errorf("Malformed update: status %d, want 400", status)
+	}
+	if status := post(http.MethodPost, "s3cret", update); status != http.StatusOK {
+		t.Errorf("Update: status %d, want 200", status)
+	}
+	fake.waitFor(t, 7, "✨ You created: 💨 Steam!")
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("StartWebhook returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("StartWebhook did not return after cancellation")
+	}
+
+	if status := post(http.MethodPost, "s3cret", update); status != http.StatusServiceUnavailable {
+		t.Errorf("Update after shutdown: status %d, want 503", status)
+	}
+	if progress, err := store.Load(telegramPlayer(7)); err != nil || !slices.Contains(progress.Discovered, "steam") {
+		t.Errorf("Saved progress = %v, %v, want steam", progress, err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.sent) == 0 || fake.sent[0].Method != "setWebhook" ||
+		fake.sent[0].Form.Get("url") != "https://example.com/telegram" || fake.sent[0].Form.Get("secret_token") != "s3cret" {
+		t.Errorf("First request = %+v, want setWebhook with the URL and secret", fake.sent)
+	}
+}
+
+func TestParseWebhookAddr(t *testing.T) {
+	if addr, path, err := parseWebhookAddr(":8443/bot/updates"); err != nil || addr != ":8443" || path != "/bot/updates" {
+		t.Errorf("parseWebhookAddr(:8443/bot/updates) = %q, %q, %v", addr, path, err)
+	}
+	for _, value := range []string{":8443", "/telegram", ""} {
+		if _, _, err := parseWebhookAddr(value); err == nil {
+			t.Errorf("parseWebhookAddr(%q) succeeded", value)
+		}
+	}
+}
+
+// recordingMessenger is an in-memory Messenger that records everything the
+// bot sends.
+type recordingMessenger struct {
+	mu      sync.Mutex
+	sent    []tgbotapi.Chattable
+	updates chan tgbotapi.Update
+
+	// files serves uploaded files by ID under /file/.
+	files      map[string]string
+	fileServer *httptest.Server
+}
+
+func newRecordingMessenger() *recordingMessenger {
+	return &recordingMessenger{updates: make(chan tgbotapi.Update, 100)}
+}
+
+func (m *recordingMessenger) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, c)
+	return tgbotapi.Message{MessageID: len(m.sent)}, nil
+}
+
+func (m *recordingMessenger) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, c)
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (m *recordingMessenger) GetFileDirectURL(fileID string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.files[fileID]; !exists {
+		return "", fmt.Errorf("file %s not found", fileID)
+	}
+	return m.fileServer.URL + "/file/" + fileID, nil
+}
+
+func (m *recordingMessenger) serveFile(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, exists := m.files[strings.TrimPrefix(r.URL.Path, "/file/")]
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+	io.WriteString(w, data)
+}
+
+func (m *recordingMessenger) MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (m *recordingMessenger) GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
+	return m.updates
+}
+
+func (m *recordingMessenger) StopReceivingUpdates() {}
+
+// take returns and forgets everything sent so far.
+func (m *recordingMessenger) take() []tgbotapi.Chattable {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sent := m.sent
+	m.sent = nil
+	return sent
+}
+
+// conversation drives a TelegramBot through a scripted chat, one message at
+// a time. Messages come from the user of a private chat unless the
+// conversation is in a group, where they come from the member set by as.
+type conversation struct {
+	t         *testing.T
+	bot       *TelegramBot
+	messenger *recordingMessenger
+	chatID    int64
+	chatType  string
+	from      *tgbotapi.User
+	updateID  int
+
+	replies []tgbotapi.Chattable
+}
+
+func newConversation(t *testing.T) *conversation {
+	t.Helper()
+
+	content, err := craft.LoadContent(data.FS)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+
+	messenger := newRecordingMessenger()
+	messenger.files = make(map[string]string)
+	messenger.fileServer = httptest.NewServer(http.HandlerFunc(messenger.serveFile))
+	t.Cleanup(messenger.fileServer.Close)
+
+	return &conversation{
+		t:         t,
+		bot:       newTelegramBot(messenger, content, craft.NewMemoryStore(), 0),
+		messenger: messenger,
+		chatID:    42,
+		chatType:  "private",
+		from:      &tgbotapi.User{ID: 42},
+	}
+}
+
+// newGroupConversation starts a conversation in a group chat.
+func newGroupConversation(t *testing.T) *conversation {
+	c := newConversation(t)
+	c.chatID = -100
+	c.chatType = "group"
+	return c
+}
+
+// as makes the following messages come from another member of the group.
+func (c *conversation) as(id int64, name string) *conversation {
+	c.from = &tgbotapi.User{ID: id, FirstName: name}
+	return c
+}
+
+// message returns a message from the current user.
+func (c *conversation) message(text string) *tgbotapi.Message {
+	message := textMessage(c.updateID, c.chatID, text)
+	message.Chat.Type = c.chatType
+	message.From = c.from
+	return message
+}
+
+// say sends text from the user and collects the bot's replies.
+func (c *conversation) say(text string) *conversation {
+	c.updateID++
+	c.bot.handleUpdate(tgbotapi.Update{
+		UpdateID: c.updateID,
+		Message:  c.message(text),
+	})
+	c.replies = c.messenger.take()
+	return c
+}
+
+// upload sends a document from the user and collects the bot's replies.
+func (c *conversation) upload(name, contents string) *conversation {
+	c.updateID++
+	fileID := fmt.Sprintf("file-%d", c.updateID)
+
+	c.messenger.mu.Lock()
+	c.messenger.files[fileID] = contents
+	c.messenger.mu.Unlock()
+
+	message := c.message("")
+	message.Document = &tgbotapi.Document{FileID: fileID, FileName: name, FileSize: len(contents)}
+	c.bot.handleUpdate(tgbotapi.Update{UpdateID: c.updateID, Message: message})
+	c.replies = c.messenger.take()
+	return c
+}
+
+// tap presses the inline button with the given callback data on the most
+// recent message.
+func (c *conversation) tap(data string) *conversation {
+	c.updateID++
+	c.bot.handleUpdate(tgbotapi.Update{
+		UpdateID: c.updateID,
+		CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:      strconv.Itoa(c.updateID),
+			From:    c.from,
+			Message: &tgbotapi.Message{MessageID: c.updateID - 1, Chat: &tgbotapi.Chat{ID: c.chatID, Type: c.chatType}},
+			Data:    data,
+		},
+	})
+	c.replies = c.messenger.take()
+	return c
+}
+
+// texts returns the text of every message sent or edited, and the answer to
+// every callback query.
+func (c *conversation) texts() []string {
+	var texts []string
+	for _, reply := range c.replies {
+		switch reply := reply.(type) {
+		case tgbotapi.MessageConfig:
+			texts = append(texts, reply.Text)
+		case tgbotapi.EditMessageTextConfig:
+			texts = append(texts, reply.Text)
+		case tgbotapi.DocumentConfig:
+			texts = append(texts, reply.Caption)
+		case tgbotapi.CallbackConfig:
+			if reply.Text != "" {
+				texts = append(texts, reply.Text)
+			}
+		}
+	}
+	return texts
+}
+
+// expect checks that the bot replied with one message per prefix.
+func (c *conversation) expect(prefixes ...string) *conversation {
+	c.t.Helper()
+
+	texts := c.texts()
+	if len(texts) != len(prefixes) {
+		c.t.Fatalf("Replies = %q, want %d starting with %q", texts, len(prefixes), prefixes)
+	}
+	for i, prefix := range prefixes {
+		if !strings.HasPrefix(texts[i], prefix) {
+			c.t.Errorf("Reply %d = %q, want prefix %q", i, texts[i], prefix)
+		}
+	}
+	return c
+}
+
+// keyboard returns the button labels of the last message sent or edited,
+// and the callback data of its inline buttons.
+func (c *conversation) keyboard() (labels [][]string, data []string) {
+	var markup any
+	for _, reply := range c.replies {
+		switch reply := reply.(type) {
+		case tgbotapi.MessageConfig:
+			markup = reply.ReplyMarkup
+		case tgbotapi.EditMessageTextConfig:
+			markup = nil
+			if reply.ReplyMarkup != nil {
+				markup = *reply.ReplyMarkup
+			}
+		}
+	}
+
+	switch markup := markup.(type) {
+	case tgbotapi.ReplyKeyboardMarkup:
+		for _, buttons := range markup.Keyboard {
+			var row []string
+			for _, button := range buttons {
+				row = append(row, button.Text)
+			}
+			labels = append(labels, row)
+		}
+	case tgbotapi.InlineKeyboardMarkup:
+		for _, buttons := range markup.InlineKeyboard {
+			var row []string
+			for _, button := range buttons {
+				row = append(row, button.Text)
+				data = append(data, *button.CallbackData)
+			}
+			labels = append(labels, row)
+		}
+	}
+	return labels, data
+}
+
+// expectKeyboard checks the keyboard of the last message sent or edited.
+func (c *conversation) expectKeyboard(want ...[]string) *conversation {
+	c.t.Helper()
+
+	if rows, _ := c.keyboard(); fmt.Sprint(rows) != fmt.Sprint(want) {
+		c.t.Errorf("Keyboard = %q, want %q", rows, want)
+	}
+	return c
+}
+
+// expectButtons checks the callback data of the inline buttons of the last
+// message sent or edited.
+func (c *conversation) expectButtons(want ...string) *conversation {
+	c.t.Helper()
+
+	if _, data := c.keyboard(); !slices.Equal(data, want) {
+		c.t.Errorf("Buttons = %q, want %q", data, want)
+	}
+	return c
+}
+
+var mainMenuKeyboard = [][]string{
+	{"🔮 Combine Elements", "📚 Discovered Elements"},
+	{"💡 Show Hints", "📥 Download Save"},
+}
+
+func TestTelegramCombineConversation(t *testing.T) {
+	c := newConversation(t)
+
+	c.say("/start").
+		expect("Welcome to Open Craft!", "Choose an option:").
+		expectKeyboard(mainMenuKeyboard...)
+
+	c.say("🔮 Combine Elements").expect("Pick the first element")
+	c.say("lava").expect("You haven't discovered this element yet!")
+	c.say("Water").expect("First element: 💧 Water\nPick the second element")
+	c.say("fire").
+		expect("✨ You created: 💨 Steam!", "Choose an option:").
+		expectKeyboard(mainMenuKeyboard...)
+
+	for _, want := range []string{"❌ These elements cannot be combined.", "❌ You already tried this"} {
+		c.say("🔮 Combine Elements")
+		c.say("earth")
+		c.say("wind").expect(want, "Choose an option:")
+	}
+
+	c.say("wind").expect()
+}
+
+func TestTelegramDiscoveredConversation(t *testing.T) {
+	c := newConversation(t)
+
+	c.say("📚 Discovered Elements").
+		expect("Select a category").
+		expectKeyboard(
+			[]string{"🌟 Primordial", "🌿 Natural"},
+			[]string{"⚗️ Chemical", "🌪️ Atmospheric"},
+			[]string{"✨ Celestial", "🧬 Biological"},
+			[]string{"⚡ Technological", "🔮 Mythical"},
+			[]string{"📋 Show All Discovered"},
+		)
+
+	c.say("🌪️ Atmospheric").
+		expect("🌪️ Atmospheric Elements:\n\nNo elements discovered in this category yet!").
+		expectKeyboard([]string{"◀️ Back to Categories", "🏠 Main Menu"})
+
+	c.say("📋 Show All Discovered").expect("All Discovered Elements (4 total):")
+	c.say("🏠 Main Menu").expectKeyboard(mainMenuKeyboard...)
+	c.say("📥 Download Save").expect("Your save file containing 4 discovered elements")
+}
+
+func TestTelegramElementPicker(t *testing.T) {
+	c := newConversation(t)
+
+	c.say("🔮 Combine Elements").
+		expect("Pick the first element or type its name:\n\n🌟 Primordial").
+		expectKeyboard([]string{"🌍 Earth", "🔥 Fire", "💧 Water"}, []string{"🌪️ Wind"}).
+		expectButtons("pick:1:earth", "pick:1:fire", "pick:1:water", "pick:1:wind")
+
+	c.tap("pick:1:lava").expect("You haven't discovered this element yet!")
+	c.tap("pick:2:fire").expect("This picker has expired")
+
+	c.tap("pick:1:water").
+		expect("First element: 💧 Water\nPick the second element").
+		expectButtons("pick:2:earth", "pick:2:fire", "pick:2:water", "pick:2:wind")
+
+	c.tap("pick:2:fire").
+		expect("💧 Water + 🔥 Fire\n\n✨ You created: 💨 Steam!", "Choose an option:").
+		expectKeyboard(mainMenuKeyboard...)
+
+	c.say("🔮 Combine Elements").
+		expectButtons("pick:1:earth", "pick:1:fire", "pick:1:water", "pick:1:wind", "page:1:1")
+	c.tap("page:1:1").
+		expect("Pick the first element or type its name:\n\n🌪️ Atmospheric").
+		expectKeyboard([]string{"💨 Steam"}, []string{"◀️ 🌟 Primordial"}).
+		expectButtons("pick:1:steam", "page:1:0")
+}
+
+func TestElementPickerPaginatesCategories(t *testing.T) {
+	content, err := craft.LoadContent(data.FS)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+
+	gameState, err := craft.LoadGameState(content, craft.NewMemoryStore(), "player")
+	if err != nil {
+		t.Fatalf("Failed to load game state: %v", err)
+	}
+	for name, element := range content.Elements {
+		if element.Category == "technological" {
+			gameState.AddDiscovered(name)
+		}
+	}
+
+	pages := pickerPages(gameState)
+	if len(pages) != 3 || pages[1].parts != 2 || len(pages[1].elements) != pickerPageSize || len(pages[2].elements) != 18-pickerPageSize {
+		t.Fatalf("Pages = %+v, want Primordial and two Technological pages", pages)
+	}
+
+	text, _ := elementPicker(gameState, 1, 2, "")
+	if !strings.HasSuffix(text, "⚡ Technological (2/2)") {
+		t.Errorf("Last page title = %q", text)
+	}
+}
+
+func TestTelegramCommands(t *testing.T) {
+	c := newConversation(t)
+
+	c.say("🔮 Combine Elements")
+	c.say("/combine watr + 🔥 Fire").expect("💧 Water + 🔥 Fire\n\n✨ You created: 💨 Steam!")
+	c.say("fire").expect()
+	c.say("/combine water").expect("Usage: /combine")
+	c.say("/combine water lava").expect(`You haven't discovered anything called "lava" yet!`)
+
+	c.say("/inventory").expect("All Discovered Elements (5 total):")
+	c.say("/category atmosferic").expect("🌪️ Atmospheric Elements:\n\n- 💨 Steam")
+	c.say("/category lava").expect(`Unknown category "lava". Try one of: primordial, natural`)
+	c.say("/progress").expect("📊 Discovered Elements: 5/")
+	c.say("/hint").expect("💡")
+	c.say("/export").expect("Your save file containing 5 discovered elements")
+	c.say("/import {}").expect("❌ Invalid save file")
+	c.say(`/import {"discovered": ["water", "fire", "earth", "wind", "lava"]}`).
+		expect("📤 Save file with 5 discovered elements\n\n➕ Gained (1): 🔥 Lava\n➖ Lost (1): 💨 Steam")
+	c.tap("import:confirm").expect("📤 Imported a save with 5 discovered elements")
+	c.say("/frobnicate").expect("Unknown command.")
+
+	c.say("/reset").
+		expect("⚠️ This throws away every discovery").
+		expectButtons("reset:confirm", "reset:cancel")
+	c.tap("reset:cancel").expect("Reset cancelled.")
+	c.say("/progress").expect("📊 Discovered Elements: 5/")
+	c.tap("reset:confirm").expect("🗑️ Progress reset.")
+	c.say("/progress").expect("📊 Discovered Elements: 4/")
+}
+
+func TestSplitElements(t *testing.T) {
+	tests := map[string][]string{
+		"water fire":            {"water", "fire"},
+		" solar system + fire ": {"solar system", "fire"},
+		"water, fire,, earth":   {"water", "fire", "earth"},
+		"":                      nil,
+	}
+	for args, want := range tests {
+		if got := splitElements(args); !slices.Equal(got, want) {
+			t.Errorf("splitElements(%q) = %q, want %q", args, got, want)
+		}
+	}
+}
+
+func TestTelegramImportUploadedSave(t *testing.T) {
+	c := newConversation(t)
+
+	c.upload("progress.json", `{"discovered": ["water", "fire", "earth", "wind", "mud"]}`).
+		expect(`❌ Invalid save file: unknown element "mud"`)
+	c.upload("progress.json", `{"discovered": ["water", "fire", "earth", "wind"], "tried": {"fire+ice": {"count": 1}}}`).
+		expect(`❌ Invalid save file: tried combination "fire+ice" has unknown element "ice"`)
+	c.upload("notes.txt", "not a save").expect("❌ Invalid save file")
+
+	save := `{"discovered": ["water", "fire", "earth", "wind", "steam", "lava"], "attempts": 2}`
+	c.upload("progress.json", save).
+		expect("📤 Save file with 6 discovered elements\n\n➕ Gained (2): 🔥 Lava, 💨 Steam\n\nReplace your progress with this save?").
+		expectButtons("import:confirm", "import:cancel")
+	c.tap("import:cancel").expect("Import cancelled.")
+	c.tap("import:confirm").expect("This import has expired")
+	c.say("/progress").expect("📊 Discovered Elements: 4/")
+
+	c.upload("progress.json", save)
+	c.say("🔮 Combine Elements")
+	c.tap("import:confirm").expect("This import has expired")
+
+	c.upload("progress.json", save)
+	c.tap("import:confirm").expect("📤 Imported a save with 6 discovered elements")
+	c.say("/progress").expect("📊 Discovered Elements: 6/")
+
+	c.upload("progress.json", save).expect("📤 Save file with 6 discovered elements\n\nIt has the same discovered elements")
+}
+
+func TestTelegramGroupCoop(t *testing.T) {
+	c := newGroupConversation(t)
+
+	c.as(1, "Ada").say("/start").expect("Welcome to Open Craft! 🌟\nCombine elements to discover new ones!\nEveryone in this chat shares one discovery pool.", "Choose an option:")
+	c.say("/leaderboard").expect("Nobody has combined anything yet!")
+
+	// Ada and Grace pick their first elements at the same time.
+	c.as(1, "Ada").say("🔮 Combine Elements")
+	c.as(2, "Grace").say("🔮 Combine Elements")
+	c.as(1, "Ada").say("water").expect("First element: 💧 Water")
+	c.as(2, "Grace").say("earth").expect("First element: 🌍 Earth")
+	c.as(1, "Ada").say("fire").expect("👤 Ada: ✨ You created: 💨 Steam!", "Choose an option:")
+	c.as(2, "Grace").say("fire").expect("👤 Grace: ✨ You created: 🔥 Lava!", "Choose an option:")
+
+	// Alan taps the picker Grace opened, then finishes with a command.
+	c.as(2, "Grace").say("🔮 Combine Elements")
+	c.as(3, "Alan").tap("pick:2:fire").expect("This picker has expired")
+	c.as(3, "Alan").tap("pick:1:steam").expect("First element: 💨 Steam")
+	c.as(3, "Alan").say("/combine earth wind").expect("👤 Alan: 🌍 Earth + 🌪️ Wind\n\n❌ These elements cannot be combined.")
+
+	c.say("/progress").expect("📊 Discovered Elements: 6/")
+	c.say("/leaderboard").expect("🏆 Leaderboard (6 elements discovered together):\n\n" +
+		"🥇 Ada: 1 discoveries, 1 attempts\n" +
+		"🥈 Grace: 1 discoveries, 1 attempts\n" +
+		"🥉 Alan: 0 discoveries, 1 attempts\n")
+
+	c.say("/reset").expect("Everyone in this chat shares one discovery pool")
+	c.say("/import {}").expect("Everyone in this chat shares one discovery pool")
+	c.upload("progress.json", `{"discovered": ["water"]}`).expect()
+
+	progress, err := c.bot.store.Load(telegramPlayer(c.chatID))
+	if err != nil {
+		t.Fatalf("Group progress was not saved: %v", err)
+	}
+	if by := progress.Discoveries["lava"].By; by != "2" {
+		t.Errorf("Lava discovered by %q, want Grace", by)
+	}
+
+	solo := newConversation(t)
+	solo.say("/leaderboard").expect("The leaderboard is for group chats")
+	solo.say("🔮 Combine Elements")
+	solo.say("water")
+	solo.say("fire").expect("✨ You created: 💨 Steam!", "Choose an option:")
+	if progress, _ := solo.bot.store.Load(telegramPlayer(solo.chatID)); len(progress.Contributions) != 0 {
+		t.Errorf("Private chat recorded contributions %v", progress.Contributions)
+	}
+}
+
+func TestTelegramIgnoresCommandsForOtherBots(t *testing.T) {
+	c := newGroupConversation(t)
+	c.bot.username = "open_craft_bot"
+
+	c.as(1, "Ada").say("/progress@open_craft_bot").expect("📊 Discovered Elements: 4/")
+	c.say("/progress@Open_Craft_Bot").expect("📊 Discovered Elements: 4/")
+	c.say("/progress@other_bot").expect()
+	c.say("/reset@other_bot").expect()
+	c.say("/frobnicate@other_bot").expect()
+	c.say("/progress").expect("📊 Discovered Elements: 4/")
+}
+
+func TestTelegramRace(t *testing.T) {
+	c := newGroupConversation(t)
+
+	c.as(1, "Ada").say("/race").expect("🏁 Race others to craft an element first!")
+	c.say("/race status").expect("You aren't in a race.")
+	c.say("/race water fire").expect("You aren't in a race.")
+
+	c.say("/race new steam").expect("🏁 Race ")
+	id, _, _ := strings.Cut(strings.TrimPrefix(c.texts()[0], "🏁 Race "), ":")
+	c.expectButtons("race:join:"+id, "race:start:"+id)
+
+	c.as(2, "Grace").tap("race:join:" + id).expect("🙋 Grace joined race " + id + " (2 racers).")
+	c.as(3, "Alan").tap("race:start:" + id).expect("Only racers can start the race")
+	c.as(3, "Alan").say("/race join nope0").expect("There is no race with that code.")
+
+	c.as(1, "Ada").say("/race water fire").expect("That race isn't running.")
+	c.say("/race start").expect("🏁 Race " + id + " has started! Be the first to craft 💨 Steam")
+	c.as(3, "Alan").say("/race join " + id).expect("That race has already started.")
+
+	c.as(1, "Ada").say("/race earth + wind").expect("👤 Ada: 🌍 Earth + 🌪️ Wind\n\n❌ These elements cannot be combined.")
+	c.as(2, "Grace").say("/race lava fire").expect("You haven't discovered anything called \"lava\" in this race yet!")
+	c.as(2, "Grace").say("/race water fire").expect("👤 Grace: 💧 Water + 🔥 Fire\n\n✨ You created: 💨 Steam!",
+		"🏆 Grace crafted 💨 Steam in ")
+	if text := c.texts()[1]; !strings.Contains(text, "1. Grace: crafted it in") || !strings.Contains(text, "2. Ada: 4 elements, 1 attempts") {
+		t.Errorf("Win announcement = %q", text)
+	}
+
+	c.as(1, "Ada").say("/race water fire").expect("That race isn't running.")
+	c.say("/race status").expect("🏆 Race " + id + " to craft 💨 Steam is over.")
+
+	// Racing doesn't touch the chat's own discoveries.
+	c.say("/progress").expect("📊 Discovered Elements: 4/")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// webhookSecretHeader carries the secret token Telegram was given in
+// setWebhook on every update it delivers.
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// parseWebhookAddr splits a -bot-webhook value like ":8443/path" into the
+// address to listen on and the path to receive updates at.
+func parseWebhookAddr(value string) (addr, path string, err error) {
+	i := strings.Index(value, "/")
+	if i < 0 {
+		return "", "", fmt.Errorf("webhook %q has no path, e.g. :8443/telegram", value)
+	}
+	if i == 0 {
+		return "", "", fmt.Errorf("webhook %q has no address, e.g. :8443/telegram", value)
+	}
+	return value[:i], value[i:], nil
+}
+
+// newWebhookSecret returns a random secret token in the alphabet Telegram
+// accepts.
+func newWebhookSecret() string {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	return hex.EncodeToString(secret)
+}
+
+// WebhookHandler returns a handler for the updates Telegram POSTs to the
+// webhook. Requests without the secret token are rejected.
+func (tb *TelegramBot) WebhookHandler(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := r.Header.Get(webhookSecretHeader)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			http.Error(w, "Invalid secret token", http.StatusUnauthorized)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&update); err != nil {
+			http.Error(w, "Invalid update", http.StatusBadRequest)
+			return
+		}
+
+		if !tb.dispatch(update) {
+			// Telegram retries the update once we are back.
+			http.Error(w, "Shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// setWebhook asks Telegram to deliver updates to url with the secret token.
+// WebhookConfig predates secret tokens, so the request is made by hand.
+func (tb *TelegramBot) setWebhook(url, secret string) error {
+	params := tgbotapi.Params{"url": url, "secret_token": secret}
+	_, err := tb.bot.MakeRequest("setWebhook", params)
+	return err
+}
+
+// StartWebhook handles the updates delivered to WebhookHandler until ctx is
+// cancelled, then waits for the updates already received to be handled and
+// saves every player. If url is set the webhook is registered with Telegram
+// first; otherwise it must already be registered with the same secret.
+func (tb *TelegramBot) StartWebhook(ctx context.Context, url, secret string) error {
+	if url != "" {
+		if err := tb.setWebhook(url, secret); err != nil {
+			return fmt.Errorf("failed to set webhook: %w", err)
+		}
+	}
+	tb.registerCommands()
+
+	<-ctx.Done()
+	return tb.stop()
+}
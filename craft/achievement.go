@@ -0,0 +1,79 @@
+package craft
+
+import (
+	"fmt"
+	"time"
+)
+
+// Achievement is a milestone defined in achievements.json. It is unlocked
+// once every condition that is set holds.
+type Achievement struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// Requires lists elements to find, a number of discoveries and
+	// discoveries per category, as for recipes.
+	Requires Requirement `json:"requires,omitzero"`
+	// Complete is a category ID whose elements must all be discovered.
+	Complete string `json:"complete,omitempty"`
+	// NoHints requires the player to never have used a hint.
+	NoHints bool `json:"no_hints,omitempty"`
+}
+
+// checkAchievement makes sure an achievement only refers to known elements
+// and categories.
+func (c *Content) checkAchievement(achievement Achievement) error {
+	if achievement.ID == "" {
+		return fmt.Errorf("achievement %q has no ID", achievement.Name)
+	}
+	if err := c.checkRequirement(&achievement.Requires); err != nil {
+		return fmt.Errorf("achievement %q %w", achievement.ID, err)
+	}
+	if _, exists := c.Category(achievement.Complete); achievement.Complete != "" && !exists {
+		return fmt.Errorf("achievement %q completes unknown category %q", achievement.ID, achievement.Complete)
+	}
+	return nil
+}
+
+func (c *Content) Achievement(id string) (Achievement, bool) {
+	for _, achievement := range c.Achievements {
+		if achievement.ID == id {
+			return achievement, true
+		}
+	}
+	return Achievement{}, false
+}
+
+func (gs *GameState) achieved(achievement Achievement) bool {
+	if achievement.NoHints && gs.Hints.Used > 0 {
+		return false
+	}
+	if len(gs.Unmet(&achievement.Requires, gs.Discovered)) > 0 {
+		return false
+	}
+	if achievement.Complete != "" {
+		for name, element := range gs.Elements {
+			if element.Category == achievement.Complete && !gs.IsDiscovered(name) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// unlockAchievements records every achievement the player has newly earned
+// and returns their IDs in the order they are defined.
+func (gs *GameState) unlockAchievements(now time.Time) []string {
+	var unlocked []string
+	for _, achievement := range gs.Achievements {
+		if _, exists := gs.Unlocked[achievement.ID]; exists {
+			continue
+		}
+		if gs.achieved(achievement) {
+			gs.Unlocked[achievement.ID] = now
+			unlocked = append(unlocked, achievement.ID)
+		}
+	}
+	return unlocked
+}
@@ -0,0 +1,75 @@
+package craft
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestCombineUnlocksAchievements(t *testing.T) {
+	fsys := fstest.MapFS{
+		"categories.json": {Data: []byte(testCategories)},
+		"elements.json": {Data: []byte(`{
+			"water": {"name": "Water", "category": "primordial"},
+			"fire": {"name": "Fire", "category": "primordial"},
+			"earth": {"name": "Earth", "category": "primordial"},
+			"wind": {"name": "Wind", "category": "primordial"},
+			"steam": {"name": "Steam", "category": "atmospheric"},
+			"lava": {"name": "Lava", "category": "natural"}
+		}`)},
+		"recipes.json": {Data: []byte(`{
+			"water+fire": "steam",
+			"earth+fire": "lava"
+		}`)},
+		"impossible.json": {Data: []byte(`[]`)},
+		"achievements.json": {Data: []byte(`[
+			{"id": "first-natural", "name": "Green Thumb", "requires": {"categories": {"natural": 1}}},
+			{"id": "complete-atmospheric", "name": "Weatherman", "complete": "atmospheric"},
+			{"id": "lava", "name": "Hot Stuff", "requires": {"elements": ["lava"]}},
+			{"id": "no-hints", "name": "Self-Taught", "requires": {"discoveries": 6}, "no_hints": true}
+		]`)},
+	}
+
+	content, err := LoadContent(fsys)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+
+	store := NewMemoryStore()
+	gameState, err := LoadGameState(content, store, "player")
+	if err != nil {
+		t.Fatalf("Failed to load game state: %v", err)
+	}
+
+	if result := gameState.Combine("water", "fire"); !slices.Equal(result.Achievements, []string{"complete-atmospheric"}) {
+		t.Errorf("water + fire unlocked %v, want complete-atmospheric", result.Achievements)
+	}
+	if result := gameState.Combine("water", "fire"); len(result.Achievements) != 0 {
+		t.Errorf("Repeating water + fire unlocked %v", result.Achievements)
+	}
+
+	gameState.Hint(time.Now(), 0)
+	if result := gameState.Combine("earth", "fire"); !slices.Equal(result.Achievements, []string{"first-natural", "lava"}) {
+		t.Errorf("earth + fire unlocked %v, want first-natural and lava", result.Achievements)
+	}
+
+	if err := gameState.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	saved, err := store.Load("player")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(saved.Unlocked) != 3 || saved.Unlocked["lava"].IsZero() {
+		t.Errorf("Saved achievements = %v, want three unlocked", saved.Unlocked)
+	}
+}
+
+func TestCheckAchievementRejectsUnknownCategory(t *testing.T) {
+	content := testContent(t)
+
+	if err := content.checkAchievement(Achievement{ID: "stars", Complete: "celestial"}); err == nil {
+		t.Error("checkAchievement accepted an unknown category")
+	}
+}
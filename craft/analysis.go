@@ -0,0 +1,244 @@
+package craft
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Analysis describes the shape of the recipe graph as seen from a set of
+// starting elements.
+type Analysis struct {
+	// Depth is the number of combination rounds needed to first reach each
+	// element, with the starting elements at depth 0.
+	Depth map[string]int
+	// Unreachable elements can never be crafted from the starting elements.
+	Unreachable []string
+	// DeadEnds are elements that are not an input of any recipe.
+	DeadEnds []string
+	// Cycles are groups of elements that can each be crafted from the
+	// others, such as moon and planet.
+	Cycles []Cycle
+}
+
+// Cycle is a group of elements that can each be crafted from the others.
+type Cycle struct {
+	// Elements holds every element of the group, sorted.
+	Elements []string
+	// Loop is the shortest chain of recipes leading from Elements[0] back
+	// to itself, as an example of how the group is connected.
+	Loop []Step
+}
+
+func (c Cycle) String() string {
+	steps := make([]string, 0, len(c.Loop))
+	for _, step := range c.Loop {
+		steps = append(steps, step.String())
+	}
+	return strings.Join(steps, ", ")
+}
+
+// Analyze computes the fixed-point closure of the recipes from start and
+// reports unreachable elements, dead ends and cycles.
+func (c *Content) Analyze(start []string) *Analysis {
+	analysis := &Analysis{
+		Depth:       make(map[string]int),
+		Unreachable: make([]string, 0),
+		DeadEnds:    make([]string, 0),
+		Cycles:      make([]Cycle, 0),
+	}
+
+	for _, element := range start {
+		analysis.Depth[element] = 0
+	}
+
+	for depth := 1; ; depth++ {
+		var reached []string
+		for key, recipe := range c.Recipes {
+			if !analysis.reachable(SplitRecipeKey(key)) || !analysis.unlocked(c, recipe.Requires) {
+				continue
+			}
+
+			for _, result := range recipe.Outputs() {
+				if _, known := analysis.Depth[result]; !known {
+					reached = append(reached, result)
+				}
+			}
+		}
+
+		if len(reached) == 0 {
+			break
+		}
+		for _, element := range reached {
+			analysis.Depth[element] = depth
+		}
+	}
+
+	inputs := make(map[string]bool)
+	graph := make(map[string][]string)
+	steps := make(map[string][]Step)
+	for _, key := range slices.Sorted(maps.Keys(c.Recipes)) {
+		recipe := c.Recipes[key]
+		recipeInputs := SplitRecipeKey(key)
+		for _, input := range slices.Compact(slices.Clone(recipeInputs)) {
+			inputs[input] = true
+			graph[input] = append(graph[input], recipe.Outputs()...)
+			for _, result := range recipe.Outputs() {
+				steps[input] = append(steps[input], Step{
+					Inputs: recipeInputs,
+					Result: result,
+					Random: !slices.Contains(recipe.Results, result),
+				})
+			}
+		}
+	}
+
+	for name := range c.Elements {
+		if _, reachable := analysis.Depth[name]; !reachable {
+			analysis.Unreachable = append(analysis.Unreachable, name)
+		}
+		if !inputs[name] {
+			analysis.DeadEnds = append(analysis.DeadEnds, name)
+		}
+	}
+	sort.Strings(analysis.Unreachable)
+	sort.Strings(analysis.DeadEnds)
+
+	for _, component := range stronglyConnected(graph) {
+		if len(component) > 1 || containsEdge(graph, component[0], component[0]) {
+			analysis.Cycles = append(analysis.Cycles, Cycle{
+				Elements: component,
+				Loop:     shortestLoop(steps, component),
+			})
+		}
+	}
+	sort.Slice(analysis.Cycles, func(i, j int) bool {
+		return analysis.Cycles[i].Elements[0] < analysis.Cycles[j].Elements[0]
+	})
+
+	return analysis
+}
+
+func (a *Analysis) reachable(elements []string) bool {
+	for _, element := range elements {
+		if _, reachable := a.Depth[element]; !reachable {
+			return false
+		}
+	}
+	return true
+}
+
+// unlocked reports whether a player who had discovered every element
+// reached so far would meet requirement.
+func (a *Analysis) unlocked(c *Content, requirement *Requirement) bool {
+	if requirement == nil {
+		return true
+	}
+	return len(c.Unmet(requirement, slices.Collect(maps.Keys(a.Depth)))) == 0
+}
+
+func containsEdge(graph map[string][]string, from, to string) bool {
+	for _, next := range graph[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// shortestLoop returns the fewest recipes that lead from the first element
+// of component back to itself through the other elements of component,
+// searching breadth-first through steps, the recipes keyed by their inputs.
+func shortestLoop(steps map[string][]Step, component []string) []Step {
+	start := component[0]
+	from := make(map[string]string)
+	via := make(map[string]Step)
+	queue := []string{start}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, step := range steps[node] {
+			if !slices.Contains(component, step.Result) {
+				continue
+			}
+			// A recipe that gives back its own input only explains the
+			// group if it is the whole group.
+			if step.Result == node && len(component) > 1 {
+				continue
+			}
+			if step.Result == start {
+				loop := []Step{step}
+				for node != start {
+					loop = append([]Step{via[node]}, loop...)
+					node = from[node]
+				}
+				return loop
+			}
+			if _, visited := from[step.Result]; !visited {
+				from[step.Result] = node
+				via[step.Result] = step
+				queue = append(queue, step.Result)
+			}
+		}
+	}
+	return nil
+}
+
+// stronglyConnected returns the strongly connected components of graph
+// using Tarjan's algorithm, each sorted by element name.
+func stronglyConnected(graph map[string][]string) [][]string {
+	nodes := make([]string, 0, len(graph))
+	for node := range graph {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	index := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var connect func(node string)
+	connect = func(node string) {
+		index[node] = len(index)
+		lowlink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, next := range graph[node] {
+			if _, visited := index[next]; !visited {
+				connect(next)
+				lowlink[node] = min(lowlink[node], lowlink[next])
+			} else if onStack[next] {
+				lowlink[node] = min(lowlink[node], index[next])
+			}
+		}
+
+		if lowlink[node] == index[node] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == node {
+					break
+				}
+			}
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+
+	for _, node := range nodes {
+		if _, visited := index[node]; !visited {
+			connect(node)
+		}
+	}
+
+	return components
+}
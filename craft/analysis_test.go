@@ -0,0 +1,71 @@
+package craft
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAnalyze(t *testing.T) {
+	fsys := fstest.MapFS{
+		"categories.json": {Data: []byte(testCategories)},
+		"elements.json": {Data: []byte(`{
+			"water": {"name": "Water", "category": "natural"}, "fire": {"name": "Fire", "category": "natural"},
+			"earth": {"name": "Earth", "category": "natural"}, "wind": {"name": "Wind", "category": "natural"},
+			"steam": {"name": "Steam", "category": "natural"}, "cloud": {"name": "Cloud", "category": "natural"},
+			"moon": {"name": "Moon", "category": "natural"}, "planet": {"name": "Planet", "category": "natural"},
+			"ghost": {"name": "Ghost", "category": "natural"}
+		}`)},
+		"recipes.json": {Data: []byte(`{
+			"water+fire": "steam",
+			"steam+wind": "cloud",
+			"earth+cloud": "moon",
+			"moon+moon": "planet",
+			"planet+wind": "moon",
+			"wind+wind": "wind"
+		}`)},
+		"impossible.json": {Data: []byte(`[]`)},
+	}
+
+	content, err := LoadContent(fsys)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+
+	analysis := content.Analyze(StartingElements)
+
+	for element, want := range map[string]int{"water": 0, "steam": 1, "cloud": 2, "moon": 3, "planet": 4} {
+		if depth, exists := analysis.Depth[element]; !exists || depth != want {
+			t.Errorf("Depth[%s] = %d, want %d", element, depth, want)
+		}
+	}
+
+	if !slices.Equal(analysis.Unreachable, []string{"ghost"}) {
+		t.Errorf("Unreachable = %v, want [ghost]", analysis.Unreachable)
+	}
+
+	if !slices.Equal(analysis.DeadEnds, []string{"ghost"}) {
+		t.Errorf("DeadEnds = %v, want [ghost]", analysis.DeadEnds)
+	}
+
+	want := []Cycle{
+		{
+			Elements: []string{"moon", "planet"},
+			Loop: []Step{
+				{Inputs: []string{"moon", "moon"}, Result: "planet"},
+				{Inputs: []string{"planet", "wind"}, Result: "moon"},
+			},
+		},
+		{
+			Elements: []string{"wind"},
+			Loop:     []Step{{Inputs: []string{"wind", "wind"}, Result: "wind"}},
+		},
+	}
+	if !reflect.DeepEqual(analysis.Cycles, want) {
+		t.Errorf("Cycles = %v, want %v", analysis.Cycles, want)
+	}
+	if got := analysis.Cycles[0].String(); got != "moon + moon -> planet, planet + wind -> moon" {
+		t.Errorf("Cycles[0].String() = %q", got)
+	}
+}
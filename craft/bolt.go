@@ -0,0 +1,90 @@
+package craft
+
+import (
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var progressBucket = []byte("progress")
+
+// BoltStore keeps the progress of every player in a single BoltDB file.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(progressBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltStore) Load(player string) (*Progress, error) {
+	var progress *Progress
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(progressBucket).Get([]byte(player))
+		if data == nil {
+			return ErrNotFound
+		}
+
+		decoded, err := DecodeProgress(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse progress of %s: %w", player, err)
+		}
+		progress = decoded
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+func (s *BoltStore) Save(player string, progress *Progress) error {
+	data, err := EncodeProgress(progress)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(progressBucket).Put([]byte(player), data)
+	})
+}
+
+func (s *BoltStore) Delete(player string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(progressBucket).Delete([]byte(player))
+	})
+}
+
+func (s *BoltStore) List() ([]string, error) {
+	var players []string
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(progressBucket).ForEach(func(k, _ []byte) error {
+			players = append(players, string(k))
+			return nil
+		})
+	})
+
+	return players, err
+}
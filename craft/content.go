@@ -0,0 +1,302 @@
+package craft
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MaxElementKeyLength is the longest element key allowed, so that keys fit
+// in the 64 bytes of callback data a Telegram button can carry.
+const MaxElementKeyLength = 48
+
+type Element struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// Category groups elements for display. Element.Category refers to ID.
+type Category struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Emoji string `json:"emoji"`
+	Order int    `json:"order"`
+}
+
+// Label is the emoji and name shown on buttons and headings.
+func (c Category) Label() string {
+	return c.Emoji + " " + c.Name
+}
+
+// Content is the static game data shared by every player: categories,
+// elements, recipes, combinations that are known to produce nothing and
+// achievements. Recipes are keyed by RecipeKey.
+type Content struct {
+	Categories   []Category
+	Elements     map[string]Element
+	Recipes      map[string]Recipe
+	Impossible   []string
+	Achievements []Achievement
+}
+
+func loadJSON(fsys fs.FS, filename string, v any) error {
+	data, err := fs.ReadFile(fsys, filename)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// LoadContent reads categories.json, elements.json, recipes.json,
+// impossible.json and, if present, achievements.json from the root of fsys.
+func LoadContent(fsys fs.FS) (*Content, error) {
+	content := &Content{
+		Categories:   make([]Category, 0),
+		Elements:     make(map[string]Element),
+		Recipes:      make(map[string]Recipe),
+		Impossible:   make([]string, 0),
+		Achievements: make([]Achievement, 0),
+	}
+
+	if err := loadJSON(fsys, "categories.json", &content.Categories); err != nil {
+		return nil, fmt.Errorf("failed to load categories: %w", err)
+	}
+	sortCategories(content.Categories)
+
+	if err := loadJSON(fsys, "elements.json", &content.Elements); err != nil {
+		return nil, fmt.Errorf("failed to load elements: %w", err)
+	}
+
+	for name, element := range content.Elements {
+		if _, exists := content.Category(element.Category); !exists {
+			return nil, fmt.Errorf("element %q has unknown category %q", name, element.Category)
+		}
+		if err := checkElementKey(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var recipes map[string]Recipe
+	if err := loadJSON(fsys, "recipes.json", &recipes); err != nil {
+		return nil, fmt.Errorf("failed to load recipes: %w", err)
+	}
+
+	var err error
+	if content.Recipes, err = canonicalRecipes(recipes); err != nil {
+		return nil, fmt.Errorf("failed to load recipes: %w", err)
+	}
+
+	for key, recipe := range content.Recipes {
+		if err := content.checkRequirement(recipe.Requires); err != nil {
+			return nil, fmt.Errorf("recipe %q: %w", key, err)
+		}
+	}
+
+	if err := loadJSON(fsys, "impossible.json", &content.Impossible); err != nil {
+		return nil, fmt.Errorf("failed to load impossible elements: %w", err)
+	}
+
+	if err := loadOptionalJSON(fsys, "achievements.json", &content.Achievements); err != nil {
+		return nil, fmt.Errorf("failed to load achievements: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, achievement := range content.Achievements {
+		if err := content.checkAchievement(achievement); err != nil {
+			return nil, err
+		}
+		if seen[achievement.ID] {
+			return nil, fmt.Errorf("achievement %q is defined twice", achievement.ID)
+		}
+		seen[achievement.ID] = true
+	}
+
+	return content, nil
+}
+
+func sortCategories(categories []Category) {
+	sort.SliceStable(categories, func(i, j int) bool {
+		return categories[i].Order < categories[j].Order
+	})
+}
+
+func (c *Content) Category(id string) (Category, bool) {
+	for _, category := range c.Categories {
+		if category.ID == id {
+			return category, true
+		}
+	}
+	return Category{}, false
+}
+
+// ValidateProgress checks that every element in progress exists, including
+// those in discovery records and tried combinations.
+func (c *Content) ValidateProgress(progress *Progress) error {
+	if len(progress.Discovered) == 0 {
+		return fmt.Errorf("save contains no discovered elements")
+	}
+
+	for _, element := range progress.Discovered {
+		if _, exists := c.Elements[element]; !exists {
+			return fmt.Errorf("unknown element %q", element)
+		}
+	}
+	for element, discovery := range progress.Discoveries {
+		for _, name := range append([]string{element}, discovery.Parents...) {
+			if _, exists := c.Elements[name]; !exists {
+				return fmt.Errorf("discovery of unknown element %q", name)
+			}
+		}
+	}
+	for key, attempt := range progress.Tried {
+		for _, name := range append(SplitRecipeKey(key), attempt.Results...) {
+			if _, exists := c.Elements[name]; !exists {
+				return fmt.Errorf("tried combination %q has unknown element %q", key, name)
+			}
+		}
+	}
+	return nil
+}
+
+func checkElementKey(name string) error {
+	if len(name) > MaxElementKeyLength {
+		return fmt.Errorf("element key %q is longer than %d bytes", name, MaxElementKeyLength)
+	}
+	return nil
+}
+
+// checkRequirement makes sure a recipe requirement only refers to known
+// elements and categories.
+func (c *Content) checkRequirement(requirement *Requirement) error {
+	if requirement == nil {
+		return nil
+	}
+
+	for _, element := range requirement.Elements {
+		if _, exists := c.Elements[element]; !exists {
+			return fmt.Errorf("requires unknown element %q", element)
+		}
+	}
+	for id := range requirement.Categories {
+		if _, exists := c.Category(id); !exists {
+			return fmt.Errorf("requires unknown category %q", id)
+		}
+	}
+	return nil
+}
+
+// Unmet describes the conditions of requirement that discovered does not
+// meet, such as "discover 🌋 Lava". It returns nil if requirement is met.
+func (c *Content) Unmet(requirement *Requirement, discovered []string) []string {
+	if requirement == nil {
+		return nil
+	}
+
+	var unmet []string
+	for _, element := range requirement.Elements {
+		if !slices.Contains(discovered, element) {
+			unmet = append(unmet, "discover "+c.Elements[element].Name)
+		}
+	}
+
+	if len(discovered) < requirement.Discoveries {
+		unmet = append(unmet, fmt.Sprintf("discover %d elements", requirement.Discoveries))
+	}
+
+	counts := make(map[string]int)
+	for _, element := range discovered {
+		counts[c.Elements[element].Category]++
+	}
+	for _, category := range c.Categories {
+		if need := requirement.Categories[category.ID]; counts[category.ID] < need {
+			unmet = append(unmet, fmt.Sprintf("discover %d %s elements", need, category.Label()))
+		}
+	}
+
+	return unmet
+}
+
+func NormalizeElementName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.ReplaceAll(name, " ", "-")
+	return name
+}
+
+// LookupRecipe returns the recipe for combining inputs in any order.
+func (c *Content) LookupRecipe(inputs ...string) (Recipe, bool) {
+	recipe, exists := c.Recipes[RecipeKey(inputs...)]
+	return recipe, exists
+}
+
+// MaxInputs returns the largest number of inputs used by any recipe.
+func (c *Content) MaxInputs() int {
+	maxInputs := 2
+	for key := range c.Recipes {
+		maxInputs = max(maxInputs, len(SplitRecipeKey(key)))
+	}
+	return maxInputs
+}
+
+func (c *Content) IsImpossible(combo string) bool {
+	key := RecipeKey(SplitRecipeKey(combo)...)
+
+	for _, impossible := range c.Impossible {
+		if RecipeKey(SplitRecipeKey(impossible)...) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// UntriedCombos lists every pair of elements that has neither a recipe nor
+// an impossible entry, for content authors filling in the recipe book.
+// Pairs that players have tried, counted by RecipeKey in tried, come first,
+// the most tried first.
+func (c *Content) UntriedCombos(tried map[string]int) []string {
+	type combo struct {
+		name  string
+		tried int
+	}
+
+	var combos []combo
+	allElements := make([]string, 0, len(c.Elements))
+
+	for elemName := range c.Elements {
+		allElements = append(allElements, elemName)
+	}
+	sort.Strings(allElements)
+
+	for i, elem1 := range allElements {
+		for j := i; j < len(allElements); j++ {
+			elem2 := allElements[j]
+
+			if _, exists := c.LookupRecipe(elem1, elem2); exists {
+				continue
+			}
+
+			if c.IsImpossible(elem1 + "+" + elem2) {
+				continue
+			}
+
+			name := fmt.Sprintf("%s + %s", c.Elements[elem1].Name, c.Elements[elem2].Name)
+			count := tried[RecipeKey(elem1, elem2)]
+			if count > 0 {
+				name += fmt.Sprintf(" (tried %d times)", count)
+			}
+			combos = append(combos, combo{name: name, tried: count})
+		}
+	}
+
+	sort.SliceStable(combos, func(i, j int) bool {
+		return combos[i].tried > combos[j].tried
+	})
+
+	names := make([]string, 0, len(combos))
+	for _, combo := range combos {
+		names = append(names, combo.name)
+	}
+	return names
+}
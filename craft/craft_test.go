@@ -0,0 +1,208 @@
+package craft
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+const testCategories = `[
+	{"id": "primordial", "name": "Primordial", "emoji": "🌟", "order": 1},
+	{"id": "natural", "name": "Natural", "emoji": "🌿", "order": 2},
+	{"id": "atmospheric", "name": "Atmospheric", "emoji": "🌪️", "order": 3},
+	{"id": "mythical", "name": "Mythical", "emoji": "🔮", "order": 4}
+]`
+
+func testContent(t *testing.T) *Content {
+	t.Helper()
+
+	fsys := fstest.MapFS{
+		"categories.json": {Data: []byte(testCategories)},
+		"elements.json": {Data: []byte(`{
+			"water": {"name": "💧 Water", "category": "primordial"},
+			"fire": {"name": "🔥 Fire", "category": "primordial"},
+			"earth": {"name": "🌍 Earth", "category": "primordial"},
+			"wind": {"name": "🌪️ Wind", "category": "primordial"},
+			"steam": {"name": "💨 Steam", "category": "atmospheric"},
+			"lava": {"name": "🌋 Lava", "category": "natural"}
+		}`)},
+		"recipes.json": {Data: []byte(`{
+			"water+fire": "steam",
+			"earth+fire": "lava"
+		}`)},
+		"impossible.json": {Data: []byte(`["wind+water"]`)},
+	}
+
+	content, err := LoadContent(fsys)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+	return content
+}
+
+func TestLoadGameStateStartsWithBaseElements(t *testing.T) {
+	store := NewMemoryStore()
+
+	gameState, err := LoadGameState(testContent(t), store, "player")
+	if err != nil {
+		t.Fatalf("Failed to load game state: %v", err)
+	}
+
+	if !slices.Equal(gameState.Discovered, StartingElements) {
+		t.Errorf("Discovered = %v, want %v", gameState.Discovered, StartingElements)
+	}
+
+	saved, err := store.Load("player")
+	if err != nil {
+		t.Fatalf("Starting progress was not saved: %v", err)
+	}
+	if !slices.Equal(saved.Discovered, StartingElements) {
+		t.Errorf("Saved = %v, want %v", saved.Discovered, StartingElements)
+	}
+}
+
+func TestCombineElements(t *testing.T) {
+	gameState, err := LoadGameState(testContent(t), NewMemoryStore(), "player")
+	if err != nil {
+		t.Fatalf("Failed to load game state: %v", err)
+	}
+	gameState.Source = SourceCLI
+
+	if result := gameState.Combine("fire", "water"); !slices.Equal(result.Results, []string{"steam"}) || !slices.Equal(result.New, []string{"steam"}) {
+		t.Errorf("fire + water = %+v, want new steam", result)
+	}
+	if !gameState.IsDiscovered("steam") {
+		t.Error("steam was not marked as discovered")
+	}
+
+	discovery := gameState.Discoveries["steam"]
+	if discovery.Time.IsZero() || !slices.Equal(discovery.Parents, []string{"fire", "water"}) || discovery.Source != SourceCLI {
+		t.Errorf("Discovery of steam = %+v, want fire + water via cli", discovery)
+	}
+
+	if result := gameState.Combine("wind", "earth"); result.Success() {
+		t.Errorf("wind + earth = %+v, want no result", result)
+	}
+
+	if gameState.Attempts != 2 || gameState.FailedAttempts != 1 {
+		t.Errorf("Attempts = %d, FailedAttempts = %d, want 2 and 1", gameState.Attempts, gameState.FailedAttempts)
+	}
+}
+
+func TestUntriedCombos(t *testing.T) {
+	combos := testContent(t).UntriedCombos(nil)
+
+	for _, combo := range []string{"💧 Water + 🔥 Fire", "🔥 Fire + 💧 Water", "💧 Water + 🌪️ Wind", "🌪️ Wind + 💧 Water"} {
+		if slices.Contains(combos, combo) {
+			t.Errorf("UntriedCombos contains known combination %q", combo)
+		}
+	}
+
+	if !slices.Contains(combos, "🌍 Earth + 🌪️ Wind") {
+		t.Errorf("UntriedCombos is missing 🌍 Earth + 🌪️ Wind: %v", combos)
+	}
+
+	// 6 elements give 21 unordered pairs including doubles, minus two
+	// recipes and one impossible entry.
+	if len(combos) != 18 {
+		t.Errorf("len(UntriedCombos) = %d, want 18", len(combos))
+	}
+}
+
+func TestCombineRemembersAttempts(t *testing.T) {
+	content := testContent(t)
+	store := NewMemoryStore()
+
+	gameState, err := LoadGameState(content, store, "player")
+	if err != nil {
+		t.Fatalf("Failed to load game state: %v", err)
+	}
+
+	if result := gameState.Combine("wind", "earth"); result.Repeated {
+		t.Error("First attempt was reported as repeated")
+	}
+	if result := gameState.Combine("earth", "wind"); !result.Repeated {
+		t.Error("Second attempt in the other order was not reported as repeated")
+	}
+	gameState.Combine("water", "fire")
+
+	if attempt := gameState.Tried["earth+wind"]; attempt.Count != 2 || len(attempt.Results) != 0 {
+		t.Errorf("Tried earth+wind = %+v, want two attempts without results", attempt)
+	}
+	if attempt := gameState.Tried["fire+water"]; !slices.Equal(attempt.Results, []string{"steam"}) {
+		t.Errorf("Tried fire+water = %+v, want steam", attempt)
+	}
+	if err := gameState.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	tried, err := CountTried(store)
+	if err != nil {
+		t.Fatalf("CountTried failed: %v", err)
+	}
+
+	combos := content.UntriedCombos(tried)
+	if combos[0] != "🌍 Earth + 🌪️ Wind (tried 2 times)" {
+		t.Errorf("UntriedCombos()[0] = %q, want the tried pair first", combos[0])
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestLoadContentCategories(t *testing.T) {
+	content := testContent(t)
+
+	if len(content.Categories) != 4 || content.Categories[0].ID != "primordial" {
+		t.Errorf("Categories = %v, want four categories ordered by Order", content.Categories)
+	}
+
+	fsys := fstest.MapFS{
+		"categories.json": {Data: []byte(testCategories)},
+		"elements.json":   {Data: []byte(`{"water": {"name": "Water", "category": "liquid"}}`)},
+		"recipes.json":    {Data: []byte(`{}`)},
+		"impossible.json": {Data: []byte(`[]`)},
+	}
+	if _, err := LoadContent(fsys); err == nil {
+		t.Error("LoadContent accepted an element with an unknown category")
+	}
+
+	fsys["elements.json"] = &fstest.MapFile{Data: []byte(`{"a-long-long-long-long-long-long-long-long-long-long": {"name": "Long", "category": "natural"}}`)}
+	if _, err := LoadContent(fsys); err == nil {
+		t.Error("LoadContent accepted an element key longer than MaxElementKeyLength")
+	}
+}
+
+func TestCombineAsCreditsMembers(t *testing.T) {
+	gameState, err := LoadGameState(testContent(t), NewMemoryStore(), "group")
+	if err != nil {
+		t.Fatalf("Failed to load game state: %v", err)
+	}
+
+	gameState.CombineAs("1", "Ada", "water", "fire")
+	gameState.CombineAs("2", "Grace", "wind", "earth")
+	gameState.CombineAs("2", "Grace", "fire", "earth")
+	gameState.CombineAs("3", "Alan", "fire", "water")
+
+	if by := gameState.Discoveries["steam"].By; by != "1" {
+		t.Errorf("steam discovered by %q, want 1", by)
+	}
+	if gameState.Attempts != 4 {
+		t.Errorf("Attempts = %d, want 4", gameState.Attempts)
+	}
+
+	var ranking []string
+	for _, member := range gameState.Leaderboard() {
+		ranking = append(ranking, fmt.Sprintf("%s:%d/%d", member.Name, member.Discoveries, member.Attempts))
+	}
+	if want := []string{"Ada:1/1", "Grace:1/2", "Alan:0/1"}; !slices.Equal(ranking, want) {
+		t.Errorf("Leaderboard = %v, want %v", ranking, want)
+	}
+}
@@ -0,0 +1,217 @@
+package craft
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+var StartingElements = []string{"water", "fire", "earth", "wind"}
+
+// GameState is a single player's view of the game: the shared content plus
+// the progress they have made so far.
+type GameState struct {
+	sync.Mutex
+	*Content
+	*Progress
+
+	// Source is recorded on every discovery made through this game state.
+	Source Source
+	// Rand picks the outcome of random recipes. If nil, the global random
+	// source is used.
+	Rand *rand.Rand
+	// Restored wraps ErrRestored if the player's save was damaged and was
+	// restored from a backup when loading. Front-ends report it to the
+	// player once and clear it.
+	Restored error
+
+	player string
+	store  ProgressStore
+}
+
+// LoadGameState restores the progress of player from store, starting a new
+// game with the starting elements if nothing has been saved yet.
+func LoadGameState(content *Content, store ProgressStore, player string) (*GameState, error) {
+	return loadGameState(content, store, player, true)
+}
+
+// loadGameState is LoadGameState, returning ErrNotFound instead of starting
+// a new game unless create is set.
+func loadGameState(content *Content, store ProgressStore, player string, create bool) (*GameState, error) {
+	gameState := &GameState{
+		Content: content,
+		player:  player,
+		store:   store,
+	}
+
+	progress, err := store.Load(player)
+	switch {
+	case err == nil:
+		gameState.Progress = progress
+	case errors.Is(err, ErrRestored):
+		gameState.Progress = progress
+		gameState.Restored = err
+	case errors.Is(err, ErrNotFound) && !create:
+		return nil, ErrNotFound
+	case errors.Is(err, ErrNotFound):
+		gameState.Progress = NewProgress()
+	default:
+		return nil, fmt.Errorf("failed to load progress: %w", err)
+	}
+
+	if len(gameState.Discovered) == 0 {
+		gameState.Discovered = slices.Clone(StartingElements)
+		if err := gameState.Save(); err != nil {
+			return nil, fmt.Errorf("failed to save progress: %w", err)
+		}
+	}
+
+	return gameState, nil
+}
+
+func (gs *GameState) Save() error {
+	return gs.store.Save(gs.player, gs.Progress)
+}
+
+// Reset throws away all progress and starts over with the starting elements.
+func (gs *GameState) Reset() error {
+	gs.Progress = NewProgress()
+	gs.Discovered = slices.Clone(StartingElements)
+	return gs.Save()
+}
+
+// Replace swaps the player's progress for an imported one after checking
+// that it only refers to known elements.
+func (gs *GameState) Replace(progress *Progress) error {
+	if err := gs.ValidateProgress(progress); err != nil {
+		return err
+	}
+
+	gs.Progress = progress
+	return gs.Save()
+}
+
+// HasTried reports whether the player has combined inputs before, in any
+// order.
+func (gs *GameState) HasTried(inputs ...string) bool {
+	_, tried := gs.Tried[RecipeKey(inputs...)]
+	return tried
+}
+
+func (gs *GameState) IsDiscovered(element string) bool {
+	return slices.Contains(gs.Discovered, element)
+}
+
+func (gs *GameState) AddDiscovered(element string) {
+	if !gs.IsDiscovered(element) {
+		gs.Discovered = append(gs.Discovered, element)
+	}
+}
+
+// SortedDiscovered returns a sorted copy of the discovered element keys.
+func (gs *GameState) SortedDiscovered() []string {
+	discovered := make([]string, len(gs.Discovered))
+	copy(discovered, gs.Discovered)
+	sort.Strings(discovered)
+	return discovered
+}
+
+// CombineResult reports what a combination produced. It is empty if the
+// inputs do not combine.
+type CombineResult struct {
+	// Results holds every element produced.
+	Results []string
+	// New holds the results that were discovered for the first time.
+	New []string
+	// Locked describes what the player still has to do before the recipe
+	// for these inputs works, if it has unmet requirements.
+	Locked []string
+	// Repeated is set if the player had already tried these inputs.
+	Repeated bool
+	// Achievements holds the IDs of achievements unlocked by this
+	// combination.
+	Achievements []string
+}
+
+func (r CombineResult) Success() bool {
+	return len(r.Results) > 0
+}
+
+// Combine looks up the recipe for inputs, in any order, and records its
+// results as discovered. A recipe whose requirements are not met yet
+// produces nothing. Every attempt is remembered in Tried, and achievements
+// are checked whenever something new is discovered.
+func (gs *GameState) Combine(inputs ...string) CombineResult {
+	key := RecipeKey(inputs...)
+	attempt, tried := gs.Tried[key]
+
+	result := gs.combine(inputs...)
+	result.Repeated = tried
+
+	attempt.Count++
+	for _, element := range result.Results {
+		if !slices.Contains(attempt.Results, element) {
+			attempt.Results = append(attempt.Results, element)
+		}
+	}
+	gs.Tried[key] = attempt
+
+	if len(result.New) > 0 {
+		result.Achievements = gs.unlockAchievements(time.Now().UTC())
+	}
+	return result
+}
+
+// CombineAs combines inputs like Combine, crediting the attempt and any new
+// discoveries to member of a shared game, whose display name is name.
+func (gs *GameState) CombineAs(member, name string, inputs ...string) CombineResult {
+	result := gs.Combine(inputs...)
+
+	contribution := gs.Contributions[member]
+	contribution.Name = name
+	contribution.Attempts++
+	contribution.Discoveries += len(result.New)
+	gs.Contributions[member] = contribution
+
+	for _, element := range result.New {
+		discovery := gs.Discoveries[element]
+		discovery.By = member
+		gs.Discoveries[element] = discovery
+	}
+	return result
+}
+
+func (gs *GameState) combine(inputs ...string) CombineResult {
+	gs.Attempts++
+
+	recipe, exists := gs.LookupRecipe(inputs...)
+	if !exists {
+		gs.FailedAttempts++
+		return CombineResult{}
+	}
+
+	if unmet := gs.Unmet(recipe.Requires, gs.Discovered); len(unmet) > 0 {
+		gs.FailedAttempts++
+		return CombineResult{Locked: unmet}
+	}
+
+	result := CombineResult{Results: recipe.Roll(gs.Rand)}
+	for _, element := range result.Results {
+		if gs.IsDiscovered(element) {
+			continue
+		}
+
+		gs.AddDiscovered(element)
+		gs.Discoveries[element] = Discovery{
+			Time:    time.Now().UTC(),
+			Parents: slices.Clone(inputs),
+			Source:  gs.Source,
+		}
+		result.New = append(result.New, element)
+	}
+	return result
+}
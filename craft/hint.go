@@ -0,0 +1,103 @@
+package craft
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Hint levels reveal progressively more of the combination being hinted at.
+const (
+	HintCategory = iota + 1
+	HintOneInput
+	HintAllInputs
+)
+
+var ErrNoHints = errors.New("nothing left to discover with your elements")
+
+// HintCooldownError is returned by GameState.Hint when the player asks for
+// a hint too soon after the last one.
+type HintCooldownError struct {
+	Remaining time.Duration
+}
+
+func (e *HintCooldownError) Error() string {
+	return fmt.Sprintf("next hint available in %s", e.Remaining.Round(time.Second))
+}
+
+// HintUsage is the hint state saved with a player's progress.
+type HintUsage struct {
+	// Target is the RecipeKey of the combination being hinted at.
+	Target string `json:"target,omitempty"`
+	// Level is the hint level last given for Target.
+	Level int `json:"level,omitempty"`
+	// Used counts every hint the player has been given.
+	Used int       `json:"used,omitempty"`
+	Last time.Time `json:"last,omitzero"`
+}
+
+// Hint points the player at a combination that makes something new.
+// Category is the category ID of one of its inputs, and Inputs holds the
+// inputs revealed at Level: none, one, then all of them.
+type Hint struct {
+	Level    int
+	Category string
+	Inputs   []string
+}
+
+// hintTargets returns the sorted keys of the recipes the player can use
+// right now that would discover a new element.
+func (gs *GameState) hintTargets() []string {
+	var targets []string
+	for key, recipe := range gs.Recipes {
+		if slices.ContainsFunc(SplitRecipeKey(key), func(input string) bool { return !gs.IsDiscovered(input) }) {
+			continue
+		}
+		if len(gs.Unmet(recipe.Requires, gs.Discovered)) > 0 {
+			continue
+		}
+		if slices.ContainsFunc(recipe.Outputs(), func(output string) bool { return !gs.IsDiscovered(output) }) {
+			targets = append(targets, key)
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// Hint suggests a combination of discovered elements that makes something
+// new. Asking again for the same combination raises the hint level until
+// every input is revealed. Hints are rate limited by cooldown.
+func (gs *GameState) Hint(now time.Time, cooldown time.Duration) (Hint, error) {
+	if wait := gs.Hints.Last.Add(cooldown).Sub(now); !gs.Hints.Last.IsZero() && wait > 0 {
+		return Hint{}, &HintCooldownError{Remaining: wait}
+	}
+
+	targets := gs.hintTargets()
+	if len(targets) == 0 {
+		return Hint{}, ErrNoHints
+	}
+
+	if slices.Contains(targets, gs.Hints.Target) {
+		gs.Hints.Level = min(gs.Hints.Level+1, HintAllInputs)
+	} else {
+		gs.Hints.Target = targets[intN(gs.Rand, len(targets))]
+		gs.Hints.Level = HintCategory
+	}
+	gs.Hints.Used++
+	gs.Hints.Last = now
+
+	inputs := SplitRecipeKey(gs.Hints.Target)
+	hint := Hint{
+		Level:    gs.Hints.Level,
+		Category: gs.Elements[inputs[0]].Category,
+	}
+	switch hint.Level {
+	case HintOneInput:
+		hint.Inputs = inputs[:1]
+	case HintAllInputs:
+		hint.Inputs = inputs
+	}
+	return hint, nil
+}
@@ -0,0 +1,77 @@
+package craft
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHintEscalates(t *testing.T) {
+	gameState, err := LoadGameState(testContent(t), NewMemoryStore(), "player")
+	if err != nil {
+		t.Fatalf("Failed to load game state: %v", err)
+	}
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	hint, err := gameState.Hint(now, time.Minute)
+	if err != nil {
+		t.Fatalf("Hint failed: %v", err)
+	}
+	if hint.Level != HintCategory || hint.Category != "primordial" || len(hint.Inputs) != 0 {
+		t.Errorf("First hint = %+v, want only the category", hint)
+	}
+	target := gameState.Hints.Target
+
+	var cooldown *HintCooldownError
+	if _, err := gameState.Hint(now.Add(30*time.Second), time.Minute); !errors.As(err, &cooldown) || cooldown.Remaining != 30*time.Second {
+		t.Errorf("Hint during cooldown = %v, want 30s remaining", err)
+	}
+
+	hint, _ = gameState.Hint(now.Add(time.Minute), time.Minute)
+	if hint.Level != HintOneInput || len(hint.Inputs) != 1 {
+		t.Errorf("Second hint = %+v, want one input", hint)
+	}
+
+	hint, _ = gameState.Hint(now.Add(2*time.Minute), time.Minute)
+	if hint.Level != HintAllInputs || RecipeKey(hint.Inputs...) != target {
+		t.Errorf("Third hint = %+v, want all inputs of %s", hint, target)
+	}
+	if gameState.Hints.Used != 3 {
+		t.Errorf("Hints.Used = %d, want 3", gameState.Hints.Used)
+	}
+
+	gameState.Combine(hint.Inputs...)
+	hint, _ = gameState.Hint(now.Add(3*time.Minute), time.Minute)
+	if hint.Level != HintCategory || gameState.Hints.Target == target {
+		t.Errorf("Hint after discovering the target = %+v for %s, want a new target", hint, gameState.Hints.Target)
+	}
+
+	gameState.Combine(SplitRecipeKey(gameState.Hints.Target)...)
+	if _, err := gameState.Hint(now.Add(4*time.Minute), time.Minute); !errors.Is(err, ErrNoHints) {
+		t.Errorf("Hint with nothing left = %v, want ErrNoHints", err)
+	}
+}
+
+func TestHintUsageIsSaved(t *testing.T) {
+	store := NewMemoryStore()
+
+	gameState, err := LoadGameState(testContent(t), store, "player")
+	if err != nil {
+		t.Fatalf("Failed to load game state: %v", err)
+	}
+	if _, err := gameState.Hint(time.Now(), 0); err != nil {
+		t.Fatalf("Hint failed: %v", err)
+	}
+	if err := gameState.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load("player")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Hints.Used != 1 || loaded.Hints.Target != gameState.Hints.Target || loaded.Hints.Last.IsZero() {
+		t.Errorf("Saved hints = %+v, want %+v", loaded.Hints, gameState.Hints)
+	}
+}
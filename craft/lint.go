@@ -0,0 +1,385 @@
+package craft
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a single problem found by Lint.
+type Issue struct {
+	File     string   `json:"file"`
+	Key      string   `json:"key,omitempty"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Key == "" {
+		return fmt.Sprintf("%s: %s: %s", i.File, i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", i.File, i.Severity, i.Key, i.Message)
+}
+
+var elementKeyPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type rawEntry struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// decodeObject decodes a JSON object keeping every entry, including
+// duplicate keys that json.Unmarshal would silently merge.
+func decodeObject(data []byte) ([]rawEntry, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	var entries []rawEntry
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		entries = append(entries, rawEntry{Key: token.(string), Value: value})
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+type linter struct {
+	issues []Issue
+}
+
+func (l *linter) report(file, key string, severity Severity, format string, args ...any) {
+	l.issues = append(l.issues, Issue{
+		File:     file,
+		Key:      key,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) hasErrors() bool {
+	return slices.ContainsFunc(l.issues, func(issue Issue) bool {
+		return issue.Severity == SeverityError
+	})
+}
+
+// splitCombo splits a recipe or impossible key into its element keys and
+// returns its canonical RecipeKey.
+func (l *linter) splitCombo(file, combo string, elements map[string]Element) (string, bool) {
+	parts := SplitRecipeKey(combo)
+	if len(parts) < 2 {
+		l.report(file, combo, SeverityError, "malformed combination, expected <element>+<element>")
+		return "", false
+	}
+
+	valid := true
+	for _, part := range parts {
+		if !elementKeyPattern.MatchString(part) {
+			l.report(file, combo, SeverityError, "malformed element key %q", part)
+			valid = false
+		} else if _, exists := elements[part]; !exists {
+			l.report(file, combo, SeverityError, "unknown element %q", part)
+			valid = false
+		}
+	}
+	return RecipeKey(parts...), valid
+}
+
+// Lint checks categories.json, elements.json, recipes.json,
+// impossible.json and achievements.json in fsys for problems that
+// LoadContent does not catch.
+func Lint(fsys fs.FS) []Issue {
+	l := &linter{}
+
+	categories := l.lintCategories(fsys)
+	elements := l.lintElements(fsys, categories)
+	recipes := l.lintRecipes(fsys, elements, categories)
+	l.lintImpossible(fsys, elements, recipes)
+	l.lintAchievements(fsys, elements, categories)
+
+	// Reachability is only meaningful once the files themselves are sound.
+	if !l.hasErrors() {
+		content := &Content{Elements: elements, Recipes: recipes}
+		for id := range categories {
+			content.Categories = append(content.Categories, Category{ID: id})
+		}
+		for _, element := range content.Analyze(StartingElements).Unreachable {
+			l.report("recipes.json", element, SeverityError, "element cannot be crafted from the starting elements")
+		}
+	}
+
+	return l.issues
+}
+
+func (l *linter) lintCategories(fsys fs.FS) map[string]bool {
+	const file = "categories.json"
+	ids := make(map[string]bool)
+
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		l.report(file, "", SeverityError, "%v", err)
+		return ids
+	}
+
+	var categories []Category
+	if err := json.Unmarshal(data, &categories); err != nil {
+		l.report(file, "", SeverityError, "invalid JSON: %v", err)
+		return ids
+	}
+
+	orders := make(map[int]string)
+	for _, category := range categories {
+		if !elementKeyPattern.MatchString(category.ID) {
+			l.report(file, category.ID, SeverityError, "malformed category ID, expected lowercase words separated by dashes")
+		}
+		if ids[category.ID] {
+			l.report(file, category.ID, SeverityError, "duplicate category ID")
+		}
+		ids[category.ID] = true
+
+		if category.Name == "" {
+			l.report(file, category.ID, SeverityError, "missing name")
+		}
+		if category.Emoji == "" {
+			l.report(file, category.ID, SeverityWarning, "missing emoji")
+		}
+
+		if previous, exists := orders[category.Order]; exists {
+			l.report(file, category.ID, SeverityWarning, "same order %d as %q", category.Order, previous)
+		}
+		orders[category.Order] = category.ID
+	}
+
+	return ids
+}
+
+func (l *linter) lintElements(fsys fs.FS, categories map[string]bool) map[string]Element {
+	const file = "elements.json"
+	elements := make(map[string]Element)
+
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		l.report(file, "", SeverityError, "%v", err)
+		return elements
+	}
+
+	entries, err := decodeObject(data)
+	if err != nil {
+		l.report(file, "", SeverityError, "invalid JSON: %v", err)
+		return elements
+	}
+
+	keys := make(map[string]string)
+	names := make(map[string]string)
+
+	for _, entry := range entries {
+		var element Element
+		if err := json.Unmarshal(entry.Value, &element); err != nil {
+			l.report(file, entry.Key, SeverityError, "invalid element: %v", err)
+			continue
+		}
+
+		if !elementKeyPattern.MatchString(entry.Key) {
+			l.report(file, entry.Key, SeverityError, "malformed element key, expected lowercase words separated by dashes")
+		}
+		if len(entry.Key) > MaxElementKeyLength {
+			l.report(file, entry.Key, SeverityError, "element key is longer than %d bytes", MaxElementKeyLength)
+		}
+
+		if previous, exists := keys[strings.ToLower(entry.Key)]; exists {
+			l.report(file, entry.Key, SeverityError, "duplicate element key (previously %q)", previous)
+		}
+		keys[strings.ToLower(entry.Key)] = entry.Key
+
+		if element.Name == "" {
+			l.report(file, entry.Key, SeverityError, "missing name")
+		} else if previous, exists := names[element.Name]; exists {
+			l.report(file, entry.Key, SeverityError, "duplicate name %q (also used by %q)", element.Name, previous)
+		}
+		names[element.Name] = entry.Key
+
+		if !categories[element.Category] {
+			l.report(file, entry.Key, SeverityError, "unknown category %q", element.Category)
+		}
+
+		elements[entry.Key] = element
+	}
+
+	return elements
+}
+
+func (l *linter) lintRecipes(fsys fs.FS, elements map[string]Element, categories map[string]bool) map[string]Recipe {
+	const file = "recipes.json"
+	recipes := make(map[string]Recipe)
+
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		l.report(file, "", SeverityError, "%v", err)
+		return recipes
+	}
+
+	entries, err := decodeObject(data)
+	if err != nil {
+		l.report(file, "", SeverityError, "invalid JSON: %v", err)
+		return recipes
+	}
+
+	seen := make(map[string]string)
+
+	for _, entry := range entries {
+		var recipe Recipe
+		if err := json.Unmarshal(entry.Value, &recipe); err != nil {
+			l.report(file, entry.Key, SeverityError, "invalid recipe: %v", err)
+			continue
+		}
+
+		key, valid := l.splitCombo(file, entry.Key, elements)
+
+		for _, result := range recipe.Outputs() {
+			if _, exists := elements[result]; !exists {
+				l.report(file, entry.Key, SeverityError, "unknown result element %q", result)
+				valid = false
+			}
+		}
+
+		if recipe.Requires != nil {
+			for _, element := range recipe.Requires.Elements {
+				if _, exists := elements[element]; !exists {
+					l.report(file, entry.Key, SeverityError, "requires unknown element %q", element)
+					valid = false
+				}
+			}
+			for id := range recipe.Requires.Categories {
+				if !categories[id] {
+					l.report(file, entry.Key, SeverityError, "requires unknown category %q", id)
+					valid = false
+				}
+			}
+		}
+
+		if !valid {
+			continue
+		}
+
+		if previous, exists := seen[key]; exists {
+			if recipes[key].Equal(recipe) {
+				l.report(file, entry.Key, SeverityWarning, "duplicate of recipe %q", previous)
+			} else {
+				l.report(file, entry.Key, SeverityError, "conflicts with recipe %q which makes %v", previous, recipes[key].Outputs())
+			}
+			continue
+		}
+		seen[key] = entry.Key
+
+		recipes[key] = recipe
+	}
+
+	return recipes
+}
+
+func (l *linter) lintImpossible(fsys fs.FS, elements map[string]Element, recipes map[string]Recipe) {
+	const file = "impossible.json"
+
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		l.report(file, "", SeverityError, "%v", err)
+		return
+	}
+
+	var impossible []string
+	if err := json.Unmarshal(data, &impossible); err != nil {
+		l.report(file, "", SeverityError, "invalid JSON: %v", err)
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, combo := range impossible {
+		key, valid := l.splitCombo(file, combo, elements)
+		if !valid {
+			continue
+		}
+
+		if seen[key] {
+			l.report(file, combo, SeverityWarning, "duplicate impossible combination")
+		}
+		seen[key] = true
+
+		if _, exists := recipes[key]; exists {
+			l.report(file, combo, SeverityError, "collides with recipe %q", key)
+		}
+	}
+}
+
+func (l *linter) lintAchievements(fsys fs.FS, elements map[string]Element, categories map[string]bool) {
+	const file = "achievements.json"
+
+	data, err := fs.ReadFile(fsys, file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		l.report(file, "", SeverityError, "%v", err)
+		return
+	}
+
+	var achievements []Achievement
+	if err := json.Unmarshal(data, &achievements); err != nil {
+		l.report(file, "", SeverityError, "invalid JSON: %v", err)
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, achievement := range achievements {
+		id := achievement.ID
+		if id == "" {
+			l.report(file, achievement.Name, SeverityError, "missing id")
+			continue
+		}
+		if seen[id] {
+			l.report(file, id, SeverityError, "duplicate achievement")
+		}
+		seen[id] = true
+
+		if achievement.Name == "" {
+			l.report(file, id, SeverityWarning, "missing name")
+		}
+		for _, element := range achievement.Requires.Elements {
+			if _, exists := elements[element]; !exists {
+				l.report(file, id, SeverityError, "requires unknown element %q", element)
+			}
+		}
+		for category := range achievement.Requires.Categories {
+			if !categories[category] {
+				l.report(file, id, SeverityError, "requires unknown category %q", category)
+			}
+		}
+		if achievement.Complete != "" && !categories[achievement.Complete] {
+			l.report(file, id, SeverityError, "completes unknown category %q", achievement.Complete)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package craft
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLint(t *testing.T) {
+	fsys := fstest.MapFS{
+		"categories.json": {Data: []byte(testCategories)},
+		"elements.json": {Data: []byte(`{
+			"water": {"name": "Water", "category": "primordial"},
+			"fire": {"name": "Fire", "category": "primodial"},
+			"earth": {"name": "Earth", "category": "primordial"},
+			"wind": {"name": "Wind", "category": "primordial"},
+			"steam": {"name": "Steam", "category": "atmospheric"},
+			"Steam": {"name": "Steam", "category": "atmospheric"},
+			"lava": {"name": "Lava", "category": "natural"},
+			"a-long-long-long-long-long-long-long-long-long-long": {"name": "Long", "category": "natural"}
+		}`)},
+		"recipes.json": {Data: []byte(`{
+			"water+fire": "steam",
+			"fire+water": "steam",
+			"earth+fire": "lava",
+			"fire+earth": "steam",
+			"water+wind+earth": ["steam", "lava"],
+			"water+ghost": {"random": [{"element": "mud"}]}
+		}`)},
+		"impossible.json": {Data: []byte(`["wind+earth", "earth+wind", "water+fire"]`)},
+	}
+
+	want := []string{
+		`elements.json: error: fire: unknown category "primodial"`,
+		`elements.json: error: Steam: malformed element key`,
+		`elements.json: error: Steam: duplicate element key (previously "steam")`,
+		`elements.json: error: Steam: duplicate name "Steam"`,
+		`elements.json: error: a-long-long-long-long-long-long-long-long-long-long: element key is longer than 48 bytes`,
+		`recipes.json: warning: fire+water: duplicate of recipe "water+fire"`,
+		`recipes.json: error: fire+earth: conflicts with recipe "earth+fire"`,
+		`recipes.json: error: water+ghost: unknown element "ghost"`,
+		`recipes.json: error: water+ghost: unknown result element "mud"`,
+		`impossible.json: warning: earth+wind: duplicate impossible combination`,
+		`impossible.json: error: water+fire: collides with recipe "fire+water"`,
+	}
+
+	issues := Lint(fsys)
+	if len(issues) != len(want) {
+		t.Errorf("Lint found %d issues, want %d:", len(issues), len(want))
+		for _, issue := range issues {
+			t.Log(issue)
+		}
+	}
+
+	for i := range min(len(issues), len(want)) {
+		if !strings.HasPrefix(issues[i].String(), want[i]) {
+			t.Errorf("Issue %d = %q, want prefix %q", i, issues[i], want[i])
+		}
+	}
+}
+
+func TestLintReportsUnreachableElements(t *testing.T) {
+	fsys := fstest.MapFS{
+		"categories.json": {Data: []byte(testCategories)},
+		"elements.json": {Data: []byte(`{
+			"water": {"name": "Water", "category": "primordial"},
+			"fire": {"name": "Fire", "category": "primordial"},
+			"earth": {"name": "Earth", "category": "primordial"},
+			"wind": {"name": "Wind", "category": "primordial"},
+			"ghost": {"name": "Ghost", "category": "mythical"}
+		}`)},
+		"recipes.json":    {Data: []byte(`{}`)},
+		"impossible.json": {Data: []byte(`[]`)},
+	}
+
+	issues := Lint(fsys)
+	if len(issues) != 1 || issues[0].Key != "ghost" {
+		t.Errorf("Lint = %v, want ghost reported as unreachable", issues)
+	}
+}
+
+func TestLintReportsUnreachableElementsAlongsideWarnings(t *testing.T) {
+	fsys := fstest.MapFS{
+		"categories.json": {Data: []byte(testCategories)},
+		"elements.json": {Data: []byte(`{
+			"water": {"name": "Water", "category": "primordial"},
+			"fire": {"name": "Fire", "category": "primordial"},
+			"earth": {"name": "Earth", "category": "primordial"},
+			"wind": {"name": "Wind", "category": "primordial"},
+			"ghost": {"name": "Ghost", "category": "mythical"}
+		}`)},
+		"recipes.json":    {Data: []byte(`{}`)},
+		"impossible.json": {Data: []byte(`["water+fire", "fire+water"]`)},
+	}
+
+	issues := Lint(fsys)
+	if len(issues) != 2 || issues[0].Severity != SeverityWarning || issues[1].Key != "ghost" {
+		t.Errorf("Lint = %v, want the duplicate warning and ghost reported as unreachable", issues)
+	}
+}
@@ -0,0 +1,127 @@
+package craft
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MatchElement finds the element among candidates that query refers to. It
+// accepts element keys and display names in any case, with or without their
+// emoji, unique prefixes and small typos. It reports false if no candidate
+// or more than one candidate matches equally well.
+func (c *Content) MatchElement(query string, candidates []string) (string, bool) {
+	forms := make(map[string][]string, len(candidates))
+	for _, name := range candidates {
+		forms[name] = []string{name, plainName(c.Elements[name].Name)}
+	}
+	return closestMatch(NormalizeElementName(query), forms)
+}
+
+// MatchCategory finds the category query refers to by ID or name, as
+// MatchElement does for elements.
+func (c *Content) MatchCategory(query string) (Category, bool) {
+	forms := make(map[string][]string, len(c.Categories))
+	for _, category := range c.Categories {
+		forms[category.ID] = []string{category.ID, plainName(category.Name)}
+	}
+
+	id, ok := closestMatch(NormalizeElementName(query), forms)
+	if !ok {
+		return Category{}, false
+	}
+	return c.Category(id)
+}
+
+// plainName normalizes a display name like "🌋 Lava" the way
+// NormalizeElementName normalizes keys, dropping emoji and punctuation.
+func plainName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' || r == '-' {
+			return r
+		}
+		return -1
+	}, name)
+	return NormalizeElementName(name)
+}
+
+// closestMatch returns the key of forms with a form equal to query, else
+// the only key with a form starting with query, else the key with the form
+// nearest to query by edit distance if that is close enough.
+func closestMatch(query string, forms map[string][]string) (string, bool) {
+	if query == "" {
+		return "", false
+	}
+
+	for key, keyForms := range forms {
+		for _, form := range keyForms {
+			if form == query {
+				return key, true
+			}
+		}
+	}
+
+	var prefixed []string
+	for key, keyForms := range forms {
+		for _, form := range keyForms {
+			if strings.HasPrefix(form, query) {
+				prefixed = append(prefixed, key)
+				break
+			}
+		}
+	}
+	if len(prefixed) == 1 {
+		return prefixed[0], true
+	}
+
+	maxDistance := 1
+	if len([]rune(query)) > 4 {
+		maxDistance = 2
+	}
+
+	best, bestDistance, tie := "", maxDistance+1, false
+	for key, keyForms := range forms {
+		distance := maxDistance + 1
+		for _, form := range keyForms {
+			distance = min(distance, editDistance(query, form))
+		}
+		switch {
+		case distance < bestDistance:
+			best, bestDistance, tie = key, distance, false
+		case distance == bestDistance:
+			tie = true
+		}
+	}
+	if best == "" || tie {
+		return "", false
+	}
+	return best, true
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent runes needed to turn a into b.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+
+	rows := make([][]int, len(s)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(t)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(s)][len(t)]
+}
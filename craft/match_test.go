@@ -0,0 +1,32 @@
+package craft
+
+import "testing"
+
+func TestMatchElement(t *testing.T) {
+	content := testContent(t)
+	candidates := []string{"water", "fire", "earth", "wind", "steam"}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"water", "water"},
+		{"  Fire ", "fire"},
+		{"💨 Steam", "steam"},
+		{"ste", "steam"},
+		{"watr", "water"},
+		{"fier", "fire"},
+		{"w", ""},
+		{"lava", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got, _ := content.MatchElement(test.query, candidates); got != test.want {
+			t.Errorf("MatchElement(%q) = %q, want %q", test.query, got, test.want)
+		}
+	}
+
+	if category, ok := content.MatchCategory("mythcal"); !ok || category.ID != "mythical" {
+		t.Errorf("MatchCategory(mythcal) = %v, want mythical", category)
+	}
+}
@@ -0,0 +1,276 @@
+package craft
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Manifest describes a content pack. Dependencies name other packs that
+// must be applied first.
+type Manifest struct {
+	Name         string   `json:"name"`
+	Version      string   `json:"version"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// Pack is a set of categories, elements, recipes, impossible combinations
+// and achievements layered over the base game. Every data file is optional.
+type Pack struct {
+	Manifest
+	Categories   []Category
+	Elements     map[string]Element
+	Recipes      map[string]Recipe
+	Impossible   []string
+	Achievements []Achievement
+}
+
+// OpenPack loads a pack from a directory or a zip file. A zip may hold the
+// pack at its root or inside a single top-level directory.
+func OpenPack(path string) (*Pack, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return LoadPack(os.DirFS(path))
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer archive.Close()
+
+	var fsys fs.FS = archive
+	if _, err := fs.Stat(fsys, "manifest.json"); err != nil {
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+			return nil, fmt.Errorf("%s: missing manifest.json", filepath.Base(path))
+		}
+		if fsys, err = fs.Sub(fsys, entries[0].Name()); err != nil {
+			return nil, err
+		}
+	}
+
+	return LoadPack(fsys)
+}
+
+func loadOptionalJSON(fsys fs.FS, filename string, v any) error {
+	err := loadJSON(fsys, filename, v)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func LoadPack(fsys fs.FS) (*Pack, error) {
+	pack := &Pack{
+		Categories:   make([]Category, 0),
+		Elements:     make(map[string]Element),
+		Recipes:      make(map[string]Recipe),
+		Impossible:   make([]string, 0),
+		Achievements: make([]Achievement, 0),
+	}
+
+	if err := loadJSON(fsys, "manifest.json", &pack.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	if pack.Name == "" {
+		return nil, fmt.Errorf("manifest is missing a name")
+	}
+
+	files := []struct {
+		name string
+		v    any
+	}{
+		{"categories.json", &pack.Categories},
+		{"elements.json", &pack.Elements},
+		{"recipes.json", &pack.Recipes},
+		{"impossible.json", &pack.Impossible},
+		{"achievements.json", &pack.Achievements},
+	}
+	for _, file := range files {
+		if err := loadOptionalJSON(fsys, file.name, file.v); err != nil {
+			return nil, fmt.Errorf("pack %s: failed to load %s: %w", pack.Name, file.name, err)
+		}
+	}
+
+	return pack, nil
+}
+
+// sortPacks orders packs so that every pack comes after its dependencies.
+func sortPacks(packs []*Pack) ([]*Pack, error) {
+	byName := make(map[string]*Pack)
+	for _, pack := range packs {
+		if _, exists := byName[pack.Name]; exists {
+			return nil, fmt.Errorf("pack %s is loaded twice", pack.Name)
+		}
+		byName[pack.Name] = pack
+	}
+
+	var sorted []*Pack
+	state := make(map[string]int) // 1 while visiting, 2 once sorted
+
+	var visit func(pack *Pack, chain []string) error
+	visit = func(pack *Pack, chain []string) error {
+		switch state[pack.Name] {
+		case 1:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(chain, pack.Name), " -> "))
+		case 2:
+			return nil
+		}
+
+		state[pack.Name] = 1
+		for _, name := range pack.Dependencies {
+			dependency, exists := byName[name]
+			if !exists {
+				return fmt.Errorf("pack %s depends on %s, which is not loaded", pack.Name, name)
+			}
+			if err := visit(dependency, append(chain, pack.Name)); err != nil {
+				return err
+			}
+		}
+		state[pack.Name] = 2
+
+		sorted = append(sorted, pack)
+		return nil
+	}
+
+	for _, pack := range packs {
+		if err := visit(pack, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// ApplyPacks layers packs over the content in dependency order. Packs may
+// add to the game but not change it: redefining a category or element, or
+// giving an existing combination a different result, is reported as a
+// conflict and leaves the content unchanged.
+func (c *Content) ApplyPacks(packs ...*Pack) error {
+	sorted, err := sortPacks(packs)
+	if err != nil {
+		return err
+	}
+
+	merged := &Content{
+		Categories:   slices.Clone(c.Categories),
+		Elements:     make(map[string]Element, len(c.Elements)),
+		Recipes:      make(map[string]Recipe, len(c.Recipes)),
+		Impossible:   slices.Clone(c.Impossible),
+		Achievements: slices.Clone(c.Achievements),
+	}
+	for name, element := range c.Elements {
+		merged.Elements[name] = element
+	}
+	for key, recipe := range c.Recipes {
+		merged.Recipes[key] = recipe
+	}
+
+	var conflicts []error
+	conflict := func(pack *Pack, format string, args ...any) {
+		conflicts = append(conflicts, fmt.Errorf("pack %s: %s", pack.Name, fmt.Sprintf(format, args...)))
+	}
+
+	for _, pack := range sorted {
+		for _, category := range pack.Categories {
+			if existing, exists := merged.Category(category.ID); exists {
+				if existing != category {
+					conflict(pack, "category %q is already defined", category.ID)
+				}
+				continue
+			}
+			merged.Categories = append(merged.Categories, category)
+		}
+
+		for name, element := range pack.Elements {
+			if err := checkElementKey(name); err != nil {
+				conflict(pack, "%v", err)
+				continue
+			}
+			if existing, exists := merged.Elements[name]; exists {
+				if existing != element {
+					conflict(pack, "element %q is already defined", name)
+				}
+				continue
+			}
+			merged.Elements[name] = element
+		}
+
+		for combo, recipe := range pack.Recipes {
+			inputs := SplitRecipeKey(combo)
+			if len(inputs) < 2 {
+				conflict(pack, "malformed recipe %q", combo)
+				continue
+			}
+
+			key := RecipeKey(inputs...)
+			if existing, exists := merged.Recipes[key]; exists {
+				if !existing.Equal(recipe) {
+					conflict(pack, "recipe %q makes %v, but it already makes %v", combo, recipe.Outputs(), existing.Outputs())
+				}
+				continue
+			}
+			merged.Recipes[key] = recipe
+		}
+
+		for _, combo := range pack.Impossible {
+			if !merged.IsImpossible(combo) {
+				merged.Impossible = append(merged.Impossible, combo)
+			}
+		}
+
+		for _, achievement := range pack.Achievements {
+			if _, exists := merged.Achievement(achievement.ID); exists {
+				conflict(pack, "achievement %q is already defined", achievement.ID)
+				continue
+			}
+			merged.Achievements = append(merged.Achievements, achievement)
+		}
+	}
+
+	for _, combo := range merged.Impossible {
+		if _, exists := merged.LookupRecipe(SplitRecipeKey(combo)...); exists {
+			conflicts = append(conflicts, fmt.Errorf("impossible combination %q has a recipe", combo))
+		}
+	}
+
+	for name, element := range merged.Elements {
+		if _, exists := merged.Category(element.Category); !exists {
+			conflicts = append(conflicts, fmt.Errorf("element %q has unknown category %q", name, element.Category))
+		}
+	}
+
+	for key, recipe := range merged.Recipes {
+		for _, element := range append(SplitRecipeKey(key), recipe.Outputs()...) {
+			if _, exists := merged.Elements[element]; !exists {
+				conflicts = append(conflicts, fmt.Errorf("recipe %q uses unknown element %q", key, element))
+			}
+		}
+		if err := merged.checkRequirement(recipe.Requires); err != nil {
+			conflicts = append(conflicts, fmt.Errorf("recipe %q %w", key, err))
+		}
+	}
+
+	for _, achievement := range merged.Achievements {
+		if err := merged.checkAchievement(achievement); err != nil {
+			conflicts = append(conflicts, err)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return errors.Join(conflicts...)
+	}
+
+	sortCategories(merged.Categories)
+	*c = *merged
+	return nil
+}
@@ -0,0 +1,136 @@
+package craft
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func testPack(t *testing.T, files map[string]string) *Pack {
+	t.Helper()
+
+	fsys := fstest.MapFS{}
+	for name, data := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+
+	pack, err := LoadPack(fsys)
+	if err != nil {
+		t.Fatalf("LoadPack failed: %v", err)
+	}
+	return pack
+}
+
+func TestApplyPacks(t *testing.T) {
+	content := testContent(t)
+
+	ocean := testPack(t, map[string]string{
+		"manifest.json":   `{"name": "ocean", "version": "1.0.0"}`,
+		"categories.json": `[{"id": "marine", "name": "Marine", "emoji": "🐠", "order": 10}]`,
+		"elements.json":   `{"sea": {"name": "🌊 Sea", "category": "marine"}}`,
+		"recipes.json":    `{"water+steam": "sea"}`,
+	})
+	deepSea := testPack(t, map[string]string{
+		"manifest.json":   `{"name": "deep-sea", "version": "0.1.0", "dependencies": ["ocean"]}`,
+		"elements.json":   `{"kraken": {"name": "🦑 Kraken", "category": "marine"}}`,
+		"recipes.json":    `{"sea+lava": "kraken", "fire+water": "steam"}`,
+		"impossible.json": `["sea+sea"]`,
+	})
+
+	if err := content.ApplyPacks(deepSea, ocean); err != nil {
+		t.Fatalf("ApplyPacks failed: %v", err)
+	}
+
+	if recipe, _ := content.LookupRecipe("lava", "sea"); !slices.Equal(recipe.Results, []string{"kraken"}) {
+		t.Errorf("lava + sea = %+v, want kraken", recipe)
+	}
+	if _, exists := content.Category("marine"); !exists {
+		t.Error("Category marine was not added")
+	}
+	if !content.IsImpossible("sea+sea") {
+		t.Error("Impossible combination sea+sea was not added")
+	}
+}
+
+func TestApplyPacksConflicts(t *testing.T) {
+	tests := map[string]map[string]string{
+		"element": {
+			"manifest.json": `{"name": "bad"}`,
+			"elements.json": `{"steam": {"name": "Hot Air", "category": "atmospheric"}}`,
+		},
+		"recipe": {
+			"manifest.json": `{"name": "bad"}`,
+			"recipes.json":  `{"fire+water": "lava"}`,
+		},
+		"impossible": {
+			"manifest.json":   `{"name": "bad"}`,
+			"impossible.json": `["fire+earth"]`,
+		},
+		"long key": {
+			"manifest.json": `{"name": "bad"}`,
+			"elements.json": `{"a-long-long-long-long-long-long-long-long-long-long": {"name": "Long", "category": "natural"}}`,
+		},
+		"category": {
+			"manifest.json": `{"name": "bad"}`,
+			"elements.json": `{"kraken": {"name": "Kraken", "category": "marine"}}`,
+		},
+		"dependency": {
+			"manifest.json": `{"name": "bad", "dependencies": ["missing"]}`,
+		},
+	}
+
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			content := testContent(t)
+			elements := len(content.Elements)
+
+			if err := content.ApplyPacks(testPack(t, files)); err == nil {
+				t.Fatal("ApplyPacks succeeded, want a conflict")
+			}
+			if len(content.Elements) != elements {
+				t.Error("Content was modified by a failed ApplyPacks")
+			}
+		})
+	}
+}
+
+func TestApplyPacksDependencyCycle(t *testing.T) {
+	a := testPack(t, map[string]string{"manifest.json": `{"name": "a", "dependencies": ["b"]}`})
+	b := testPack(t, map[string]string{"manifest.json": `{"name": "b", "dependencies": ["a"]}`})
+
+	err := testContent(t).ApplyPacks(a, b)
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("ApplyPacks: err = %v, want dependency cycle", err)
+	}
+}
+
+func TestOpenPackZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ocean.zip")
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := zip.NewWriter(file)
+	for name, data := range map[string]string{
+		"ocean/manifest.json": `{"name": "ocean", "version": "1.0.0"}`,
+		"ocean/elements.json": `{"sea": {"name": "Sea", "category": "natural"}}`,
+	} {
+		w, _ := archive.Create(name)
+		w.Write([]byte(data))
+	}
+	archive.Close()
+	file.Close()
+
+	pack, err := OpenPack(path)
+	if err != nil {
+		t.Fatalf("OpenPack failed: %v", err)
+	}
+	if pack.Name != "ocean" || pack.Version != "1.0.0" || len(pack.Elements) != 1 {
+		t.Errorf("OpenPack = %+v", pack)
+	}
+}
@@ -0,0 +1,344 @@
+package craft
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var ErrUnreachable = errors.New("element cannot be crafted")
+
+// Step is a single combination in a crafting path.
+type Step struct {
+	Inputs []string
+	Result string
+	// Random is set if the recipe only produces Result some of the time.
+	Random bool
+}
+
+// String formats the step as "planet + rocket -> moon".
+func (s Step) String() string {
+	return strings.Join(s.Inputs, " + ") + " -> " + s.Result
+}
+
+// maxPathStates bounds the sets of elements ShortestPath searches through
+// before it settles for a plan that is short but may not be the shortest.
+const maxPathStates = 20_000
+
+// ShortestPath returns the fewest combinations needed to craft target when
+// starting from the elements in have, ordered so that every input of a step
+// is available by the time it is reached. Intermediate elements that are
+// needed more than once are only crafted once. Elements required by a
+// recipe are crafted before it, but discovery counts and category milestones
+// are not planned for.
+//
+// The search is exact for recipe graphs the size of the base game. When a
+// graph is too large to search exhaustively, ShortestPath falls back to
+// combining the best plan for each element on its own, which is short but
+// not always the fewest combinations.
+func (c *Content) ShortestPath(have []string, target string) ([]Step, error) {
+	if _, exists := c.Elements[target]; !exists {
+		return nil, fmt.Errorf("unknown element %q", target)
+	}
+	if slices.Contains(have, target) {
+		return nil, nil
+	}
+
+	best, err := c.searchPath(have, target)
+	if errors.Is(err, errSearchTooLarge) {
+		best, err = c.mergePaths(have, target)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.orderSteps(best, target), nil
+}
+
+var errSearchTooLarge = errors.New("too many combinations to search")
+
+// pathAction is a recipe making one element on the way to a target, with
+// its inputs and required elements as indexes into the searched elements.
+type pathAction struct {
+	step   Step
+	needs  []int
+	result int
+}
+
+// elementSet is a set of indexes into the searched elements.
+type elementSet []uint64
+
+func (s elementSet) has(i int) bool {
+	return s[i/64]&(1<<(i%64)) != 0
+}
+
+func (s elementSet) with(i int) elementSet {
+	set := slices.Clone(s)
+	set[i/64] |= 1 << (i % 64)
+	return set
+}
+
+func (s elementSet) key() string {
+	var key strings.Builder
+	for _, word := range s {
+		fmt.Fprintf(&key, "%016x", word)
+	}
+	return key.String()
+}
+
+// searchPath searches best-first through the sets of elements that can be
+// crafted from have, one combination at a time, and returns the recipe that
+// made each element of the smallest set containing target. Only elements
+// that target can be crafted from are considered.
+func (c *Content) searchPath(have []string, target string) (map[string]Step, error) {
+	keys := slices.Sorted(maps.Keys(c.Recipes))
+
+	useful := map[string]bool{target: true}
+	for changed := true; changed; {
+		changed = false
+		for _, key := range keys {
+			recipe := c.Recipes[key]
+			if !slices.ContainsFunc(recipe.Outputs(), func(result string) bool { return useful[result] }) {
+				continue
+			}
+			for _, element := range append(SplitRecipeKey(key), recipe.requiredElements()...) {
+				if !useful[element] {
+					useful[element] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	elements := slices.Sorted(maps.Keys(useful))
+	index := make(map[string]int, len(elements))
+	for i, element := range elements {
+		index[element] = i
+	}
+
+	var actions []pathAction
+	for _, key := range keys {
+		inputs := SplitRecipeKey(key)
+		recipe := c.Recipes[key]
+		for _, result := range recipe.Outputs() {
+			if !useful[result] || slices.Contains(have, result) {
+				continue
+			}
+			action := pathAction{
+				step: Step{
+					Inputs: inputs,
+					Result: result,
+					Random: !slices.Contains(recipe.Results, result),
+				},
+				result: index[result],
+			}
+			for _, element := range append(slices.Clone(inputs), recipe.requiredElements()...) {
+				action.needs = append(action.needs, index[element])
+			}
+			actions = append(actions, action)
+		}
+	}
+
+	start := make(elementSet, (len(elements)+63)/64)
+	for _, element := range have {
+		if i, exists := index[element]; exists {
+			start = start.with(i)
+		}
+	}
+
+	// estimate returns a lower bound on the combinations still needed from
+	// set: every element takes one more than the slowest of its inputs.
+	goal := index[target]
+	levels := make([]int, len(elements))
+	estimate := func(set elementSet) (int, bool) {
+		for i := range levels {
+			levels[i] = -1
+			if set.has(i) {
+				levels[i] = 0
+			}
+		}
+		for changed := true; changed; {
+			changed = false
+			for _, action := range actions {
+				level := 0
+				for _, need := range action.needs {
+					if levels[need] < 0 {
+						level = -1
+						break
+					}
+					level = max(level, levels[need])
+				}
+				if level >= 0 && (levels[action.result] < 0 || level+1 < levels[action.result]) {
+					levels[action.result] = level + 1
+					changed = true
+				}
+			}
+		}
+		return levels[goal], levels[goal] >= 0
+	}
+
+	type node struct {
+		set    elementSet
+		step   Step
+		parent *node
+		cost   int
+	}
+
+	h, reachable := estimate(start)
+	if !reachable {
+		return nil, fmt.Errorf("%w: %s", ErrUnreachable, target)
+	}
+
+	// queue holds the nodes still to visit, bucketed by their combinations
+	// so far plus the estimate of those left.
+	queue := make([][]*node, h+1)
+	queue[h] = []*node{{set: start}}
+	visited := make(map[string]bool)
+
+	for priority := h; priority < len(queue); priority++ {
+		for len(queue[priority]) > 0 {
+			current := queue[priority][0]
+			queue[priority] = queue[priority][1:]
+
+			key := current.set.key()
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+			if len(visited) > maxPathStates {
+				return nil, errSearchTooLarge
+			}
+
+			if current.set.has(goal) {
+				best := make(map[string]Step)
+				for n := current; n.parent != nil; n = n.parent {
+					best[n.step.Result] = n.step
+				}
+				return best, nil
+			}
+
+			for _, action := range actions {
+				if current.set.has(action.result) || slices.ContainsFunc(action.needs, func(need int) bool {
+					return !current.set.has(need)
+				}) {
+					continue
+				}
+
+				set := current.set.with(action.result)
+				if visited[set.key()] {
+					continue
+				}
+				h, reachable := estimate(set)
+				if !reachable {
+					continue
+				}
+
+				next := &node{set: set, step: action.step, parent: current, cost: current.cost + 1}
+				for len(queue) <= next.cost+h {
+					queue = append(queue, nil)
+				}
+				queue[next.cost+h] = append(queue[next.cost+h], next)
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnreachable, target)
+}
+
+// mergePaths finds the fewest elements that have to be crafted to make
+// each element on its own, and returns the recipe for each. Merging the
+// plans of the inputs of a recipe can craft more than the fewest elements
+// in total, so the result is an upper bound.
+func (c *Content) mergePaths(have []string, target string) (map[string]Step, error) {
+	// crafted holds, for every reachable element, the set of elements that
+	// have to be crafted to make it; best holds the recipe that achieves it.
+	crafted := make(map[string]map[string]bool)
+	best := make(map[string]Step)
+	for _, element := range have {
+		crafted[element] = map[string]bool{}
+	}
+
+	keys := slices.Sorted(maps.Keys(c.Recipes))
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, key := range keys {
+			inputs := SplitRecipeKey(key)
+			recipe := c.Recipes[key]
+
+			plan := make(map[string]bool)
+			reachable := true
+			for _, input := range append(slices.Clone(inputs), recipe.requiredElements()...) {
+				inputPlan, exists := crafted[input]
+				if !exists {
+					reachable = false
+					break
+				}
+				for element := range inputPlan {
+					plan[element] = true
+				}
+			}
+			if !reachable {
+				continue
+			}
+
+			for _, result := range recipe.Outputs() {
+				if plan[result] {
+					continue
+				}
+
+				if current, exists := crafted[result]; exists && len(current) <= len(plan)+1 {
+					continue
+				}
+
+				resultPlan := make(map[string]bool, len(plan)+1)
+				for element := range plan {
+					resultPlan[element] = true
+				}
+				resultPlan[result] = true
+
+				crafted[result] = resultPlan
+				best[result] = Step{
+					Inputs: inputs,
+					Result: result,
+					Random: !slices.Contains(recipe.Results, result),
+				}
+				changed = true
+			}
+		}
+	}
+
+	if _, reachable := crafted[target]; !reachable {
+		return nil, fmt.Errorf("%w: %s", ErrUnreachable, target)
+	}
+	return best, nil
+}
+
+// orderSteps returns the steps in best that target is crafted from, each
+// after the steps that make its inputs and required elements.
+func (c *Content) orderSteps(best map[string]Step, target string) []Step {
+	var steps []Step
+	added := make(map[string]bool)
+
+	var visit func(element string)
+	visit = func(element string) {
+		step, needed := best[element]
+		if !needed || added[element] {
+			return
+		}
+		added[element] = true
+
+		for _, input := range step.Inputs {
+			visit(input)
+		}
+		for _, element := range c.Recipes[RecipeKey(step.Inputs...)].requiredElements() {
+			visit(element)
+		}
+		steps = append(steps, step)
+	}
+	visit(target)
+
+	return steps
+}
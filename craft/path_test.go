@@ -0,0 +1,135 @@
+package craft
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func pathContent(t *testing.T) *Content {
+	t.Helper()
+
+	fsys := fstest.MapFS{
+		"categories.json": {Data: []byte(testCategories)},
+		"elements.json": {Data: []byte(`{
+			"water": {"name": "Water", "category": "natural"}, "fire": {"name": "Fire", "category": "natural"},
+			"earth": {"name": "Earth", "category": "natural"}, "wind": {"name": "Wind", "category": "natural"},
+			"steam": {"name": "Steam", "category": "natural"}, "lava": {"name": "Lava", "category": "natural"},
+			"stone": {"name": "Stone", "category": "natural"}, "cloud": {"name": "Cloud", "category": "natural"},
+			"rain": {"name": "Rain", "category": "natural"}, "ghost": {"name": "Ghost", "category": "natural"}
+		}`)},
+		"recipes.json": {Data: []byte(`{
+			"water+fire": "steam",
+			"earth+fire": "lava",
+			"lava+water": "stone",
+			"steam+wind": "cloud",
+			"cloud+water": "rain",
+			"stone+steam": "rain"
+		}`)},
+		"impossible.json": {Data: []byte(`[]`)},
+	}
+
+	content, err := LoadContent(fsys)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+	return content
+}
+
+func TestShortestPath(t *testing.T) {
+	content := pathContent(t)
+
+	steps, err := content.ShortestPath(StartingElements, "rain")
+	if err != nil {
+		t.Fatalf("ShortestPath failed: %v", err)
+	}
+
+	want := []Step{
+		{Inputs: []string{"fire", "water"}, Result: "steam"},
+		{Inputs: []string{"steam", "wind"}, Result: "cloud"},
+		{Inputs: []string{"cloud", "water"}, Result: "rain"},
+	}
+	if !slices.EqualFunc(steps, want, func(a, b Step) bool {
+		return slices.Equal(a.Inputs, b.Inputs) && a.Result == b.Result && a.Random == b.Random
+	}) {
+		t.Errorf("ShortestPath(rain) = %v, want %v", steps, want)
+	}
+}
+
+func TestShortestPathFromDiscovered(t *testing.T) {
+	steps, err := pathContent(t).ShortestPath([]string{"water", "cloud"}, "rain")
+	if err != nil {
+		t.Fatalf("ShortestPath failed: %v", err)
+	}
+	if len(steps) != 1 {
+		t.Errorf("ShortestPath(rain) = %v, want a single step", steps)
+	}
+
+	steps, err = pathContent(t).ShortestPath(StartingElements, "water")
+	if err != nil || len(steps) != 0 {
+		t.Errorf("ShortestPath(water) = %v, %v, want no steps", steps, err)
+	}
+}
+
+func TestShortestPathUnreachable(t *testing.T) {
+	content := pathContent(t)
+
+	if _, err := content.ShortestPath(StartingElements, "ghost"); !errors.Is(err, ErrUnreachable) {
+		t.Errorf("ShortestPath(ghost): err = %v, want ErrUnreachable", err)
+	}
+	if _, err := content.ShortestPath(StartingElements, "nothing"); err == nil {
+		t.Error("ShortestPath of an unknown element succeeded")
+	}
+}
+
+func TestShortestPathSharesIntermediates(t *testing.T) {
+	fsys := fstest.MapFS{
+		"categories.json": {Data: []byte(testCategories)},
+		"elements.json": {Data: []byte(`{
+			"a": {"name": "A", "category": "natural"}, "b": {"name": "B", "category": "natural"},
+			"d": {"name": "D", "category": "natural"}, "f": {"name": "F", "category": "natural"},
+			"g": {"name": "G", "category": "natural"}, "h": {"name": "H", "category": "natural"},
+			"i": {"name": "I", "category": "natural"}, "j": {"name": "J", "category": "natural"}
+		}`)},
+		"recipes.json": {Data: []byte(`{
+			"a+b": "j",
+			"b+j": "g",
+			"a+g": "f",
+			"f+j": "d",
+			"a+a": "h",
+			"b+h": "i",
+			"b+i": "f"
+		}`)},
+		"impossible.json": {Data: []byte(`[]`)},
+	}
+	content, err := LoadContent(fsys)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+
+	// Making f on its own is as quick through h and i as through j and g,
+	// but only j is needed for d as well.
+	steps, err := content.ShortestPath([]string{"a", "b"}, "d")
+	if err != nil {
+		t.Fatalf("ShortestPath failed: %v", err)
+	}
+
+	want := []Step{
+		{Inputs: []string{"a", "b"}, Result: "j"},
+		{Inputs: []string{"b", "j"}, Result: "g"},
+		{Inputs: []string{"a", "g"}, Result: "f"},
+		{Inputs: []string{"f", "j"}, Result: "d"},
+	}
+	if !slices.EqualFunc(steps, want, func(a, b Step) bool {
+		return slices.Equal(a.Inputs, b.Inputs) && a.Result == b.Result && a.Random == b.Random
+	}) {
+		t.Errorf("ShortestPath(d) = %v, want %v", steps, want)
+	}
+
+	// The fallback for large graphs plans f on its own.
+	best, err := content.mergePaths([]string{"a", "b"}, "d")
+	if err != nil || len(content.orderSteps(best, "d")) != 5 {
+		t.Errorf("mergePaths(d) = %v, %v, want a plan of 5 steps", best, err)
+	}
+}
@@ -0,0 +1,159 @@
+package craft
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ProgressVersion is the save format written by EncodeProgress. Version 1
+// saves were a bare JSON array of discovered element keys.
+const ProgressVersion = 2
+
+// Source names the front-end a discovery was made through.
+type Source string
+
+const (
+	SourceCLI      Source = "cli"
+	SourceAPI      Source = "api"
+	SourceTelegram Source = "telegram"
+)
+
+type Discovery struct {
+	Time    time.Time `json:"time,omitzero"`
+	Parents []string  `json:"parents,omitempty"`
+	Source  Source    `json:"source,omitempty"`
+	// By is the member of a shared game who made the discovery.
+	By string `json:"by,omitempty"`
+}
+
+// Contribution counts what one member of a shared game has done.
+type Contribution struct {
+	Name        string `json:"name,omitempty"`
+	Attempts    int    `json:"attempts"`
+	Discoveries int    `json:"discoveries"`
+}
+
+// Attempt records how often a player tried a combination and every element
+// it has produced for them.
+type Attempt struct {
+	Count   int      `json:"count"`
+	Results []string `json:"results,omitempty"`
+}
+
+// Progress is everything persisted for a single player. Tried is keyed by
+// RecipeKey and Unlocked maps achievement IDs to when they were unlocked.
+// Contributions is only kept for games shared by several members, keyed by
+// member ID.
+type Progress struct {
+	Version        int                     `json:"version"`
+	Discovered     []string                `json:"discovered"`
+	Discoveries    map[string]Discovery    `json:"discoveries,omitempty"`
+	Tried          map[string]Attempt      `json:"tried,omitempty"`
+	Hints          HintUsage               `json:"hints,omitzero"`
+	Unlocked       map[string]time.Time    `json:"achievements,omitempty"`
+	Contributions  map[string]Contribution `json:"contributions,omitempty"`
+	Attempts       int                     `json:"attempts"`
+	FailedAttempts int                     `json:"failed_attempts"`
+}
+
+func NewProgress() *Progress {
+	return &Progress{
+		Version:       ProgressVersion,
+		Discovered:    make([]string, 0),
+		Discoveries:   make(map[string]Discovery),
+		Tried:         make(map[string]Attempt),
+		Unlocked:      make(map[string]time.Time),
+		Contributions: make(map[string]Contribution),
+	}
+}
+
+// DecodeProgress parses a save file of any known version and migrates it to
+// the current one.
+func DecodeProgress(data []byte) (*Progress, error) {
+	progress := NewProgress()
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &progress.Discovered); err != nil {
+			return nil, err
+		}
+		return progress, nil
+	}
+
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, err
+	}
+
+	if progress.Version > ProgressVersion {
+		return nil, fmt.Errorf("unsupported save version %d", progress.Version)
+	}
+
+	if progress.Discovered == nil {
+		progress.Discovered = make([]string, 0)
+	}
+	if progress.Discoveries == nil {
+		progress.Discoveries = make(map[string]Discovery)
+	}
+	if progress.Tried == nil {
+		progress.Tried = make(map[string]Attempt)
+	}
+	if progress.Unlocked == nil {
+		progress.Unlocked = make(map[string]time.Time)
+	}
+	if progress.Contributions == nil {
+		progress.Contributions = make(map[string]Contribution)
+	}
+	progress.Version = ProgressVersion
+
+	return progress, nil
+}
+
+// Member is one entry of a leaderboard.
+type Member struct {
+	ID string
+	Contribution
+}
+
+// Leaderboard ranks the members of a shared game by discoveries, then by
+// fewest attempts.
+func (p *Progress) Leaderboard() []Member {
+	members := make([]Member, 0, len(p.Contributions))
+	for id, contribution := range p.Contributions {
+		members = append(members, Member{ID: id, Contribution: contribution})
+	}
+
+	slices.SortFunc(members, func(a, b Member) int {
+		return cmp.Or(
+			cmp.Compare(b.Discoveries, a.Discoveries),
+			cmp.Compare(a.Attempts, b.Attempts),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	return members
+}
+
+// DiffDiscovered lists the elements discovered in to but not in from, and
+// those discovered in from but not in to, each sorted.
+func DiffDiscovered(from, to *Progress) (gained, lost []string) {
+	for _, element := range to.Discovered {
+		if !slices.Contains(from.Discovered, element) {
+			gained = append(gained, element)
+		}
+	}
+	for _, element := range from.Discovered {
+		if !slices.Contains(to.Discovered, element) {
+			lost = append(lost, element)
+		}
+	}
+	slices.Sort(gained)
+	slices.Sort(lost)
+	return gained, lost
+}
+
+func EncodeProgress(progress *Progress) ([]byte, error) {
+	progress.Version = ProgressVersion
+	return json.MarshalIndent(progress, "", "  ")
+}
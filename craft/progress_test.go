@@ -0,0 +1,88 @@
+package craft
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDecodeProgressMigratesBareArray(t *testing.T) {
+	progress, err := DecodeProgress([]byte(`["water", "fire", "steam"]`))
+	if err != nil {
+		t.Fatalf("DecodeProgress failed: %v", err)
+	}
+
+	if progress.Version != ProgressVersion {
+		t.Errorf("Version = %d, want %d", progress.Version, ProgressVersion)
+	}
+	if !slices.Equal(progress.Discovered, []string{"water", "fire", "steam"}) {
+		t.Errorf("Discovered = %v", progress.Discovered)
+	}
+	if progress.Discoveries == nil {
+		t.Error("Discoveries was not initialized")
+	}
+}
+
+func TestProgressRoundTrip(t *testing.T) {
+	progress := NewProgress()
+	progress.Discovered = []string{"water", "fire", "steam"}
+	progress.Discoveries["steam"] = Discovery{Parents: []string{"water", "fire"}, Source: SourceTelegram}
+	progress.Attempts = 4
+	progress.FailedAttempts = 3
+
+	data, err := EncodeProgress(progress)
+	if err != nil {
+		t.Fatalf("EncodeProgress failed: %v", err)
+	}
+
+	decoded, err := DecodeProgress(data)
+	if err != nil {
+		t.Fatalf("DecodeProgress failed: %v", err)
+	}
+
+	if !slices.Equal(decoded.Discovered, progress.Discovered) ||
+		decoded.Discoveries["steam"].Source != SourceTelegram ||
+		decoded.Attempts != 4 || decoded.FailedAttempts != 3 {
+		t.Errorf("Round trip = %+v, want %+v", decoded, progress)
+	}
+}
+
+func TestDecodeProgressRejectsNewerVersion(t *testing.T) {
+	_, err := DecodeProgress([]byte(`{"version": 99, "discovered": []}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("DecodeProgress of version 99: err = %v, want unsupported version", err)
+	}
+}
+
+func TestValidateProgressChecksEveryElement(t *testing.T) {
+	content := testContent(t)
+
+	tests := map[string]string{
+		`{"discovered": ["water", "fire", "steam"], "discoveries": {"steam": {"parents": ["water", "fire"]}}}`: "",
+		`{"discovered": ["water", "mud"]}`:                                          "unknown element",
+		`{"discovered": ["water"], "discoveries": {"mud": {}}}`:                     "discovery of unknown element",
+		`{"discovered": ["water"], "discoveries": {"steam": {"parents": ["ice"]}}}`: "discovery of unknown element",
+		`{"discovered": ["water"], "tried": {"ice+water": {"count": 1}}}`:           "tried combination",
+	}
+	for save, want := range tests {
+		progress, err := DecodeProgress([]byte(save))
+		if err != nil {
+			t.Fatalf("DecodeProgress(%s) failed: %v", save, err)
+		}
+
+		err = content.ValidateProgress(progress)
+		if want == "" && err != nil || want != "" && (err == nil || !strings.Contains(err.Error(), want)) {
+			t.Errorf("ValidateProgress(%s) = %v, want %q", save, err, want)
+		}
+	}
+}
+
+func TestDiffDiscovered(t *testing.T) {
+	from := &Progress{Discovered: []string{"water", "fire", "steam"}}
+	to := &Progress{Discovered: []string{"water", "lava", "fire", "earth"}}
+
+	gained, lost := DiffDiscovered(from, to)
+	if !slices.Equal(gained, []string{"earth", "lava"}) || !slices.Equal(lost, []string{"steam"}) {
+		t.Errorf("DiffDiscovered = %v, %v, want [earth lava], [steam]", gained, lost)
+	}
+}
@@ -0,0 +1,447 @@
+package craft
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRaceNotFound   = errors.New("race not found")
+	ErrRaceStarted    = errors.New("race has already started")
+	ErrRaceNotRunning = errors.New("race is not running")
+	ErrNotRacing      = errors.New("not taking part in this race")
+	ErrNotDiscovered  = errors.New("one or more of these elements have not been discovered yet")
+)
+
+// RaceState is the stage a race is in.
+type RaceState string
+
+const (
+	RaceLobby    RaceState = "lobby"
+	RaceRunning  RaceState = "running"
+	RaceFinished RaceState = "finished"
+	RaceExpired  RaceState = "expired"
+)
+
+// Target depths picked by RaceTarget: deep enough to take a few minutes,
+// shallow enough to be found without hints.
+const (
+	minRaceDepth = 3
+	maxRaceDepth = 6
+)
+
+// DefaultRaceLimit is how long a race runs unless it is given a limit.
+const DefaultRaceLimit = 10 * time.Minute
+
+// RaceTarget picks a target element for a race from seed. The same seed
+// always picks the same target for the same content.
+func (c *Content) RaceTarget(seed uint64) string {
+	analysis := c.Analyze(StartingElements)
+
+	var candidates, fallback []string
+	for element, depth := range analysis.Depth {
+		switch {
+		case depth >= minRaceDepth && depth <= maxRaceDepth:
+			candidates = append(candidates, element)
+		case depth >= 2:
+			fallback = append(fallback, element)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = fallback
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.Strings(candidates)
+	return candidates[rand.New(rand.NewPCG(seed, 0)).IntN(len(candidates))]
+}
+
+// Race is a competition to craft Target first. Every racer starts from the
+// starting elements with their own game, and random recipes roll the same
+// way for everyone because each game is seeded with Seed. Races are safe
+// for concurrent use.
+type Race struct {
+	ID     string
+	Target string
+	Seed   uint64
+	// Limit is how long the race runs before it expires without a winner.
+	Limit time.Duration
+
+	content *Content
+	created time.Time
+
+	mu      sync.Mutex
+	state   RaceState
+	started time.Time
+	winner  string
+	racers  []*racer
+}
+
+type racer struct {
+	id   string
+	name string
+	// index is the racer's public number, in the order they joined.
+	index int
+	// token is the secret that identifies the racer to front-ends that
+	// cannot vouch for them, such as the API.
+	token string
+	game  *GameState
+	// time is how long the racer took to craft the target, if they did.
+	time     time.Duration
+	finished bool
+}
+
+// NewRace creates a race lobby for target. An empty target is picked with
+// RaceTarget, and a zero seed is replaced by a random one short enough to
+// share.
+func NewRace(content *Content, target string, seed uint64, limit time.Duration) (*Race, error) {
+	if seed == 0 {
+		seed = 1 + rand.Uint64N(999_999)
+	}
+	if target == "" {
+		target = content.RaceTarget(seed)
+	}
+
+	if _, exists := content.Elements[target]; !exists {
+		return nil, fmt.Errorf("unknown element %q", target)
+	}
+	if slices.Contains(StartingElements, target) {
+		return nil, fmt.Errorf("%q is a starting element", target)
+	}
+	if _, reachable := content.Analyze(StartingElements).Depth[target]; !reachable {
+		return nil, fmt.Errorf("%w: %s", ErrUnreachable, target)
+	}
+
+	return &Race{
+		Target:  target,
+		Seed:    seed,
+		Limit:   limit,
+		content: content,
+		state:   RaceLobby,
+	}, nil
+}
+
+func (r *Race) racer(id string) *racer {
+	for _, racer := range r.racers {
+		if racer.id == id {
+			return racer
+		}
+	}
+	return nil
+}
+
+// expire ends a running race whose time limit has passed. Callers must hold
+// r.mu.
+func (r *Race) expire(now time.Time) {
+	if r.state == RaceRunning && r.Limit > 0 && now.Sub(r.started) >= r.Limit {
+		r.state = RaceExpired
+	}
+}
+
+// Join adds a racer to the lobby, or renames them if they have already
+// joined. Racers without a name are called "Racer" and their number. It
+// returns the racer's secret token, which ByToken looks them up by.
+func (r *Race) Join(id, name string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if racer := r.racer(id); racer != nil {
+		if name != "" {
+			racer.name = name
+		}
+		return racer.token, nil
+	}
+	if r.state != RaceLobby {
+		return "", ErrRaceStarted
+	}
+	if name == "" {
+		name = fmt.Sprintf("Racer %d", len(r.racers)+1)
+	}
+
+	game := &GameState{
+		Content:  r.content,
+		Progress: NewProgress(),
+		Rand:     rand.New(rand.NewPCG(r.Seed, 0)),
+		player:   id,
+		store:    NewMemoryStore(),
+	}
+	game.Discovered = slices.Clone(StartingElements)
+
+	token := make([]byte, 16)
+	crand.Read(token)
+
+	r.racers = append(r.racers, &racer{
+		id:    id,
+		name:  name,
+		index: len(r.racers),
+		token: hex.EncodeToString(token),
+		game:  game,
+	})
+	return r.racers[len(r.racers)-1].token, nil
+}
+
+// ByToken returns the ID of the racer the token was given to.
+func (r *Race) ByToken(token string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, racer := range r.racers {
+		if token != "" && racer.token == token {
+			return racer.id, nil
+		}
+	}
+	return "", ErrNotRacing
+}
+
+// Start starts the clock.
+func (r *Race) Start(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state != RaceLobby {
+		return ErrRaceStarted
+	}
+	r.state = RaceRunning
+	r.started = now
+	return nil
+}
+
+// Combine combines inputs in the game of racer. The first racer to craft
+// the target wins and ends the race.
+func (r *Race) Combine(id string, now time.Time, inputs ...string) (CombineResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expire(now)
+	if r.state != RaceRunning {
+		return CombineResult{}, ErrRaceNotRunning
+	}
+
+	racer := r.racer(id)
+	if racer == nil {
+		return CombineResult{}, ErrNotRacing
+	}
+	for _, input := range inputs {
+		if !racer.game.IsDiscovered(input) {
+			return CombineResult{}, ErrNotDiscovered
+		}
+	}
+
+	result := racer.game.Combine(inputs...)
+	// Achievements belong to the player's own game, not to the race.
+	result.Achievements = nil
+
+	if racer.game.IsDiscovered(r.Target) {
+		racer.finished = true
+		racer.time = now.Sub(r.started)
+		r.winner = id
+		r.state = RaceFinished
+	}
+	return result, nil
+}
+
+// Discovered returns the sorted elements racer has discovered.
+func (r *Race) Discovered(id string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	racer := r.racer(id)
+	if racer == nil {
+		return nil, ErrNotRacing
+	}
+	return racer.game.SortedDiscovered(), nil
+}
+
+// RaceStatus is a snapshot of a race.
+type RaceStatus struct {
+	ID     string
+	Target string
+	Seed   uint64
+	Limit  time.Duration
+	State  RaceState
+	// Elapsed is the time since the race started, stopping when it ends.
+	Elapsed time.Duration
+	Winner  string
+	// Standings lists the racers, the winner first and then by the number
+	// of elements discovered and the fewest attempts.
+	Standings []Standing
+}
+
+type Standing struct {
+	Racer string
+	// Index is the racer's public number, in the order they joined.
+	Index      int
+	Name       string
+	Discovered int
+	Attempts   int
+	Finished   bool
+	// Time is how long the racer took to craft the target.
+	Time time.Duration
+}
+
+func (r *Race) Status(now time.Time) RaceStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expire(now)
+	status := RaceStatus{
+		ID:        r.ID,
+		Target:    r.Target,
+		Seed:      r.Seed,
+		Limit:     r.Limit,
+		State:     r.state,
+		Winner:    r.winner,
+		Standings: make([]Standing, 0, len(r.racers)),
+	}
+
+	switch r.state {
+	case RaceRunning:
+		status.Elapsed = now.Sub(r.started)
+	case RaceFinished:
+		status.Elapsed = r.racer(r.winner).time
+	case RaceExpired:
+		status.Elapsed = r.Limit
+	}
+
+	for _, racer := range r.racers {
+		status.Standings = append(status.Standings, Standing{
+			Racer:      racer.id,
+			Index:      racer.index,
+			Name:       racer.name,
+			Discovered: len(racer.game.Discovered),
+			Attempts:   racer.game.Attempts,
+			Finished:   racer.finished,
+			Time:       racer.time,
+		})
+	}
+	sort.SliceStable(status.Standings, func(i, j int) bool {
+		a, b := status.Standings[i], status.Standings[j]
+		if a.Finished != b.Finished {
+			return a.Finished
+		}
+		if a.Discovered != b.Discovered {
+			return a.Discovered > b.Discovered
+		}
+		return a.Attempts < b.Attempts
+	})
+	return status
+}
+
+// Remaining returns how long a running race has left, or zero if it has
+// no time limit or is not running.
+func (s RaceStatus) Remaining() time.Duration {
+	if s.State != RaceRunning || s.Limit == 0 {
+		return 0
+	}
+	return s.Limit - s.Elapsed
+}
+
+// raceRetention is how long Races keeps a race after creating it.
+const raceRetention = 24 * time.Hour
+
+// raceCodeAlphabet leaves out characters that are easily confused.
+const raceCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// Races holds the races of a server, keyed by a short code that players
+// share to join. It also remembers the race each racer joined last.
+type Races struct {
+	content *Content
+
+	mu      sync.Mutex
+	races   map[string]*Race
+	current map[string]string
+}
+
+func NewRaces(content *Content) *Races {
+	return &Races{
+		content: content,
+		races:   make(map[string]*Race),
+		current: make(map[string]string),
+	}
+}
+
+// Create creates a race lobby with NewRace and gives it a code.
+func (rs *Races) Create(target string, seed uint64, limit time.Duration) (*Race, error) {
+	race, err := NewRace(rs.content, target, seed, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	now := time.Now()
+	for id, old := range rs.races {
+		if now.Sub(old.created) > raceRetention {
+			delete(rs.races, id)
+		}
+	}
+	for racer, id := range rs.current {
+		if rs.races[id] == nil {
+			delete(rs.current, racer)
+		}
+	}
+
+	race.created = now
+	for race.ID == "" || rs.races[race.ID] != nil {
+		var code strings.Builder
+		for range 5 {
+			code.WriteByte(raceCodeAlphabet[rand.IntN(len(raceCodeAlphabet))])
+		}
+		race.ID = code.String()
+	}
+	rs.races[race.ID] = race
+	return race, nil
+}
+
+// Get looks up a race by its code, in any case.
+func (rs *Races) Get(id string) (*Race, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	race, exists := rs.races[strings.ToUpper(id)]
+	if !exists {
+		return nil, ErrRaceNotFound
+	}
+	return race, nil
+}
+
+// Join adds racer to a race and makes it their current race, returning
+// the racer's token as Race.Join does.
+func (rs *Races) Join(id, racer, name string) (*Race, string, error) {
+	race, err := rs.Get(id)
+	if err != nil {
+		return nil, "", err
+	}
+	token, err := race.Join(racer, name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rs.mu.Lock()
+	rs.current[racer] = race.ID
+	rs.mu.Unlock()
+	return race, token, nil
+}
+
+// Current returns the race racer joined last.
+func (rs *Races) Current(racer string) (*Race, error) {
+	rs.mu.Lock()
+	id, exists := rs.current[racer]
+	rs.mu.Unlock()
+
+	if !exists {
+		return nil, ErrNotRacing
+	}
+	return rs.Get(id)
+}
@@ -0,0 +1,151 @@
+package craft
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRaceTarget(t *testing.T) {
+	content := pathContent(t)
+
+	if target := content.RaceTarget(1); target != "rain" {
+		t.Errorf("RaceTarget(1) = %q, want rain, the only element three rounds deep", target)
+	}
+
+	race, err := NewRace(content, "", 0, time.Minute)
+	if err != nil {
+		t.Fatalf("NewRace failed: %v", err)
+	}
+	if race.Seed == 0 || race.Target != "rain" {
+		t.Errorf("NewRace picked seed %d and target %q", race.Seed, race.Target)
+	}
+
+	for _, target := range []string{"water", "ghost", "nothing"} {
+		if _, err := NewRace(content, target, 1, time.Minute); err == nil {
+			t.Errorf("NewRace(%s) succeeded", target)
+		}
+	}
+}
+
+func TestRace(t *testing.T) {
+	content := pathContent(t)
+	race, err := NewRace(content, "rain", 1, time.Minute)
+	if err != nil {
+		t.Fatalf("NewRace failed: %v", err)
+	}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	adaToken, _ := race.Join("ada", "Ada")
+	bobToken, _ := race.Join("bob", "Bob")
+
+	if adaToken == "" || adaToken == bobToken {
+		t.Errorf("Tokens %q and %q, want two different secrets", adaToken, bobToken)
+	}
+	if token, _ := race.Join("ada", "Ada L."); token != adaToken {
+		t.Errorf("Joining again gave token %q, want %q", token, adaToken)
+	}
+	if id, err := race.ByToken(bobToken); err != nil || id != "bob" {
+		t.Errorf("ByToken(bob's token) = %q, %v", id, err)
+	}
+	for _, token := range []string{"", "bob"} {
+		if _, err := race.ByToken(token); !errors.Is(err, ErrNotRacing) {
+			t.Errorf("ByToken(%q) returned %v, want ErrNotRacing", token, err)
+		}
+	}
+
+	if _, err := race.Combine("ada", start, "water", "fire"); !errors.Is(err, ErrRaceNotRunning) {
+		t.Errorf("Combine in the lobby returned %v, want ErrRaceNotRunning", err)
+	}
+	if err := race.Start(start); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if _, err := race.Join("eve", "Eve"); !errors.Is(err, ErrRaceStarted) {
+		t.Errorf("Join after the start returned %v, want ErrRaceStarted", err)
+	}
+	if _, err := race.Combine("eve", start, "water", "fire"); !errors.Is(err, ErrNotRacing) {
+		t.Errorf("Combine by an outsider returned %v, want ErrNotRacing", err)
+	}
+	if _, err := race.Combine("bob", start, "lava", "water"); !errors.Is(err, ErrNotDiscovered) {
+		t.Errorf("Combine with undiscovered lava returned %v, want ErrNotDiscovered", err)
+	}
+
+	race.Combine("bob", start.Add(time.Second), "earth", "wind")
+	steps := [][]string{{"water", "fire"}, {"steam", "wind"}, {"cloud", "water"}}
+	for i, inputs := range steps {
+		result, err := race.Combine("ada", start.Add(time.Duration(i+1)*10*time.Second), inputs...)
+		if err != nil || !result.Success() {
+			t.Fatalf("Combine(%v) = %+v, %v", inputs, result, err)
+		}
+	}
+
+	if _, err := race.Combine("bob", start.Add(time.Minute), "water", "fire"); !errors.Is(err, ErrRaceNotRunning) {
+		t.Errorf("Combine after the win returned %v, want ErrRaceNotRunning", err)
+	}
+
+	status := race.Status(start.Add(time.Hour))
+	if status.State != RaceFinished || status.Winner != "ada" || status.Elapsed != 30*time.Second {
+		t.Errorf("Status = %+v, want Ada winning after 30s", status)
+	}
+	if len(status.Standings) != 2 {
+		t.Fatalf("Standings = %+v, want two racers", status.Standings)
+	}
+	if ada := status.Standings[0]; ada.Name != "Ada L." || ada.Index != 0 || !ada.Finished || ada.Attempts != 3 || ada.Discovered != 7 || ada.Time != 30*time.Second {
+		t.Errorf("Winner standing = %+v", ada)
+	}
+	if bob := status.Standings[1]; bob.Index != 1 || bob.Finished || bob.Attempts != 1 || bob.Discovered != 4 {
+		t.Errorf("Runner-up standing = %+v", bob)
+	}
+}
+
+func TestRaceExpires(t *testing.T) {
+	race, err := NewRace(pathContent(t), "rain", 1, time.Minute)
+	if err != nil {
+		t.Fatalf("NewRace failed: %v", err)
+	}
+
+	start := time.Now()
+	race.Join("ada", "Ada")
+	race.Start(start)
+
+	if status := race.Status(start.Add(20 * time.Second)); status.Remaining() != 40*time.Second {
+		t.Errorf("Remaining = %s, want 40s", status.Remaining())
+	}
+	if _, err := race.Combine("ada", start.Add(time.Minute), "water", "fire"); !errors.Is(err, ErrRaceNotRunning) {
+		t.Errorf("Combine after the limit returned %v, want ErrRaceNotRunning", err)
+	}
+	if status := race.Status(start.Add(time.Hour)); status.State != RaceExpired || status.Winner != "" {
+		t.Errorf("Status = %+v, want expired without a winner", status)
+	}
+}
+
+func TestRaces(t *testing.T) {
+	races := NewRaces(pathContent(t))
+
+	race, err := races.Create("", 1, time.Minute)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(race.ID) != 5 {
+		t.Errorf("Race code = %q, want five characters", race.ID)
+	}
+
+	if _, err := races.Current("ada"); !errors.Is(err, ErrNotRacing) {
+		t.Errorf("Current before joining returned %v, want ErrNotRacing", err)
+	}
+	if _, _, err := races.Join(strings.ToLower(race.ID), "ada", "Ada"); err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+	if current, err := races.Current("ada"); err != nil || current != race {
+		t.Errorf("Current = %v, %v, want the joined race", current, err)
+	}
+	races.Join(race.ID, "bob", "")
+	races.Join(race.ID, "ada", "")
+	if standings := race.Status(time.Now()).Standings; standings[0].Name != "Ada" || standings[1].Name != "Racer 2" {
+		t.Errorf("Standings = %+v, want Ada kept and bob named by number", standings)
+	}
+	if _, err := races.Get("NOPE0"); !errors.Is(err, ErrRaceNotFound) {
+		t.Errorf("Get of an unknown code returned %v, want ErrRaceNotFound", err)
+	}
+}
@@ -0,0 +1,195 @@
+package craft
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Outcome is one of the weighted random results of a recipe.
+type Outcome struct {
+	Element string `json:"element"`
+	Weight  int    `json:"weight,omitempty"`
+}
+
+// Requirement gates a recipe behind the player's progress. Every condition
+// that is set must hold before the recipe works.
+type Requirement struct {
+	// Elements must all have been discovered.
+	Elements []string `json:"elements,omitempty"`
+	// Discoveries is the least number of elements that must have been
+	// discovered.
+	Discoveries int `json:"discoveries,omitempty"`
+	// Categories maps category IDs to the least number of their elements
+	// that must have been discovered.
+	Categories map[string]int `json:"categories,omitempty"`
+}
+
+func (r *Requirement) Equal(other *Requirement) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return slices.Equal(r.Elements, other.Elements) &&
+		r.Discoveries == other.Discoveries &&
+		maps.Equal(r.Categories, other.Categories)
+}
+
+// Recipe is what a combination of elements produces: every element in
+// Results, plus one element picked from Random by weight. If Requires is
+// set the recipe only works once the player meets it.
+//
+// In recipes.json a recipe is either a single element key, a list of
+// element keys, or an object with "results", "random" and "requires"
+// fields.
+type Recipe struct {
+	Results  []string     `json:"results,omitempty"`
+	Random   []Outcome    `json:"random,omitempty"`
+	Requires *Requirement `json:"requires,omitempty"`
+}
+
+func (r *Recipe) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return fmt.Errorf("empty recipe")
+	}
+
+	*r = Recipe{}
+	switch data[0] {
+	case '"':
+		var result string
+		if err := json.Unmarshal(data, &result); err != nil {
+			return err
+		}
+		r.Results = []string{result}
+	case '[':
+		if err := json.Unmarshal(data, &r.Results); err != nil {
+			return err
+		}
+	default:
+		type plain Recipe
+		if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Results) == 0 && len(r.Random) == 0 {
+		return fmt.Errorf("recipe has no results")
+	}
+	if r.Requires != nil {
+		if r.Requires.Discoveries < 0 {
+			return fmt.Errorf("negative discoveries requirement")
+		}
+		for category, count := range r.Requires.Categories {
+			if count < 0 {
+				return fmt.Errorf("negative requirement for category %q", category)
+			}
+		}
+	}
+	for i := range r.Random {
+		if r.Random[i].Weight < 0 {
+			return fmt.Errorf("negative weight for %q", r.Random[i].Element)
+		}
+		if r.Random[i].Weight == 0 {
+			r.Random[i].Weight = 1
+		}
+	}
+	return nil
+}
+
+// Outputs returns every element the recipe can produce.
+func (r Recipe) Outputs() []string {
+	outputs := slices.Clone(r.Results)
+	for _, outcome := range r.Random {
+		if !slices.Contains(outputs, outcome.Element) {
+			outputs = append(outputs, outcome.Element)
+		}
+	}
+	return outputs
+}
+
+// Roll returns the elements produced by one use of the recipe. A nil rng
+// uses the global random source.
+func (r Recipe) Roll(rng *rand.Rand) []string {
+	results := slices.Clone(r.Results)
+	if len(r.Random) == 0 {
+		return results
+	}
+
+	total := 0
+	for _, outcome := range r.Random {
+		total += outcome.Weight
+	}
+
+	pick := intN(rng, total)
+	for _, outcome := range r.Random {
+		if pick < outcome.Weight {
+			if !slices.Contains(results, outcome.Element) {
+				results = append(results, outcome.Element)
+			}
+			break
+		}
+		pick -= outcome.Weight
+	}
+	return results
+}
+
+// intN returns a random number in [0, n) from rng, or from the global random
+// source if rng is nil.
+func intN(rng *rand.Rand, n int) int {
+	if rng != nil {
+		return rng.IntN(n)
+	}
+	return rand.IntN(n)
+}
+
+func (r Recipe) requiredElements() []string {
+	if r.Requires == nil {
+		return nil
+	}
+	return r.Requires.Elements
+}
+
+func (r Recipe) Equal(other Recipe) bool {
+	return slices.Equal(r.Results, other.Results) &&
+		slices.Equal(r.Random, other.Random) &&
+		r.Requires.Equal(other.Requires)
+}
+
+// RecipeKey returns the canonical key of a combination: the inputs sorted
+// and joined with "+", so that the order they are combined in does not
+// matter.
+func RecipeKey(inputs ...string) string {
+	sorted := slices.Clone(inputs)
+	sort.Strings(sorted)
+	return strings.Join(sorted, "+")
+}
+
+// SplitRecipeKey returns the inputs of a recipe key.
+func SplitRecipeKey(key string) []string {
+	return strings.Split(key, "+")
+}
+
+// canonicalRecipes re-keys recipes as read from a file by RecipeKey.
+func canonicalRecipes(recipes map[string]Recipe) (map[string]Recipe, error) {
+	canonical := make(map[string]Recipe, len(recipes))
+
+	for combo, recipe := range recipes {
+		inputs := SplitRecipeKey(combo)
+		if len(inputs) < 2 {
+			return nil, fmt.Errorf("recipe %q needs at least two inputs", combo)
+		}
+
+		key := RecipeKey(inputs...)
+		if existing, exists := canonical[key]; exists && !existing.Equal(recipe) {
+			return nil, fmt.Errorf("recipe %q is defined twice with different results", combo)
+		}
+		canonical[key] = recipe
+	}
+
+	return canonical, nil
+}
@@ -0,0 +1,183 @@
+package craft
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRecipeUnmarshal(t *testing.T) {
+	var recipes map[string]Recipe
+	err := json.Unmarshal([]byte(`{
+		"water+fire": "steam",
+		"water+earth": ["mud", "plant"],
+		"earth+fire+wind": {"results": ["ash"], "random": [{"element": "gold", "weight": 1}, {"element": "coal", "weight": 9}]}
+	}`), &recipes)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if !slices.Equal(recipes["water+fire"].Results, []string{"steam"}) {
+		t.Errorf("water+fire = %+v", recipes["water+fire"])
+	}
+	if !slices.Equal(recipes["water+earth"].Results, []string{"mud", "plant"}) {
+		t.Errorf("water+earth = %+v", recipes["water+earth"])
+	}
+	if outputs := recipes["earth+fire+wind"].Outputs(); !slices.Equal(outputs, []string{"ash", "gold", "coal"}) {
+		t.Errorf("earth+fire+wind outputs = %v", outputs)
+	}
+
+	for _, invalid := range []string{`[]`, `{}`, `{"random": [{"element": "gold", "weight": -1}]}`} {
+		var recipe Recipe
+		if err := json.Unmarshal([]byte(invalid), &recipe); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded", invalid)
+		}
+	}
+}
+
+func TestRecipeRollIsSeedable(t *testing.T) {
+	recipe := Recipe{
+		Results: []string{"ash"},
+		Random:  []Outcome{{Element: "gold", Weight: 1}, {Element: "coal", Weight: 3}},
+	}
+
+	roll := func(seed uint64) [][]string {
+		rng := rand.New(rand.NewPCG(seed, 0))
+		var rolls [][]string
+		for range 20 {
+			rolls = append(rolls, recipe.Roll(rng))
+		}
+		return rolls
+	}
+
+	first, second := roll(42), roll(42)
+	if !slices.EqualFunc(first, second, slices.Equal) {
+		t.Error("Rolls with the same seed differ")
+	}
+
+	for _, results := range first {
+		if len(results) != 2 || results[0] != "ash" {
+			t.Errorf("Roll = %v, want ash plus one random outcome", results)
+		}
+	}
+}
+
+func TestCombineMultipleInputsAndResults(t *testing.T) {
+	fsys := fstest.MapFS{
+		"categories.json": {Data: []byte(testCategories)},
+		"elements.json": {Data: []byte(`{
+			"water": {"name": "Water", "category": "primordial"},
+			"fire": {"name": "Fire", "category": "primordial"},
+			"earth": {"name": "Earth", "category": "primordial"},
+			"wind": {"name": "Wind", "category": "primordial"},
+			"mud": {"name": "Mud", "category": "natural"},
+			"plant": {"name": "Plant", "category": "natural"},
+			"storm": {"name": "Storm", "category": "atmospheric"}
+		}`)},
+		"recipes.json": {Data: []byte(`{
+			"water+earth": ["mud", "plant"],
+			"water+wind+fire": "storm"
+		}`)},
+		"impossible.json": {Data: []byte(`[]`)},
+	}
+
+	content, err := LoadContent(fsys)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+	if content.MaxInputs() != 3 {
+		t.Errorf("MaxInputs = %d, want 3", content.MaxInputs())
+	}
+
+	gameState, err := LoadGameState(content, NewMemoryStore(), "player")
+	if err != nil {
+		t.Fatalf("Failed to load game state: %v", err)
+	}
+
+	result := gameState.Combine("earth", "water")
+	if !slices.Equal(result.Results, []string{"mud", "plant"}) || !slices.Equal(result.New, result.Results) {
+		t.Errorf("earth + water = %+v, want new mud and plant", result)
+	}
+
+	if result := gameState.Combine("fire", "wind", "water"); !slices.Equal(result.Results, []string{"storm"}) {
+		t.Errorf("fire + wind + water = %+v, want storm", result)
+	}
+
+	if result := gameState.Combine("water", "earth"); len(result.New) != 0 {
+		t.Errorf("Repeated combination reported new elements: %v", result.New)
+	}
+}
+
+func TestCombineRequirements(t *testing.T) {
+	fsys := fstest.MapFS{
+		"categories.json": {Data: []byte(testCategories)},
+		"elements.json": {Data: []byte(`{
+			"water": {"name": "Water", "category": "primordial"},
+			"fire": {"name": "Fire", "category": "primordial"},
+			"earth": {"name": "Earth", "category": "primordial"},
+			"wind": {"name": "Wind", "category": "primordial"},
+			"steam": {"name": "Steam", "category": "atmospheric"},
+			"lava": {"name": "Lava", "category": "natural"},
+			"dragon": {"name": "Dragon", "category": "mythical"}
+		}`)},
+		"recipes.json": {Data: []byte(`{
+			"water+fire": "steam",
+			"earth+fire": "lava",
+			"fire+wind": {"results": ["dragon"], "requires": {"elements": ["lava"], "discoveries": 6, "categories": {"atmospheric": 1}}}
+		}`)},
+		"impossible.json": {Data: []byte(`[]`)},
+	}
+
+	content, err := LoadContent(fsys)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+
+	gameState, err := LoadGameState(content, NewMemoryStore(), "player")
+	if err != nil {
+		t.Fatalf("Failed to load game state: %v", err)
+	}
+
+	result := gameState.Combine("wind", "fire")
+	want := []string{"discover Lava", "discover 6 elements", "discover 1 🌪️ Atmospheric elements"}
+	if result.Success() || !slices.Equal(result.Locked, want) {
+		t.Errorf("Locked combination = %+v, want locked by %v", result, want)
+	}
+	if gameState.FailedAttempts != 1 {
+		t.Errorf("FailedAttempts = %d, want 1", gameState.FailedAttempts)
+	}
+
+	gameState.Combine("earth", "fire")
+	gameState.Combine("water", "fire")
+
+	if result := gameState.Combine("wind", "fire"); !slices.Equal(result.New, []string{"dragon"}) {
+		t.Errorf("Unlocked combination = %+v, want new dragon", result)
+	}
+
+	steps, err := content.ShortestPath(StartingElements, "dragon")
+	if err != nil {
+		t.Fatalf("ShortestPath failed: %v", err)
+	}
+	if len(steps) != 2 || steps[0].Result != "lava" {
+		t.Errorf("ShortestPath = %+v, want lava before dragon", steps)
+	}
+
+	if unreachable := content.Analyze([]string{"fire", "wind"}).Unreachable; !slices.Contains(unreachable, "dragon") {
+		t.Errorf("Unreachable = %v, want dragon locked behind lava", unreachable)
+	}
+}
+
+func TestLoadContentRejectsUnknownRequirement(t *testing.T) {
+	fsys := fstest.MapFS{
+		"categories.json": {Data: []byte(testCategories)},
+		"elements.json":   {Data: []byte(`{"water": {"name": "Water", "category": "primordial"}, "fire": {"name": "Fire", "category": "primordial"}}`)},
+		"recipes.json":    {Data: []byte(`{"water+fire": {"results": ["water"], "requires": {"categories": {"celestial": 2}}}}`)},
+		"impossible.json": {Data: []byte(`[]`)},
+	}
+
+	if _, err := LoadContent(fsys); err == nil {
+		t.Error("LoadContent accepted a requirement on an unknown category")
+	}
+}
@@ -0,0 +1,85 @@
+package craft
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Sessions caches the game states of players so that every request for the
+// same player shares one GameState. Callers must hold the GameState lock
+// while using it.
+type Sessions struct {
+	content *Content
+	store   ProgressStore
+	source  Source
+
+	mu    sync.Mutex
+	games map[string]*GameState
+}
+
+func NewSessions(content *Content, store ProgressStore, source Source) *Sessions {
+	return &Sessions{
+		content: content,
+		store:   store,
+		source:  source,
+		games:   make(map[string]*GameState),
+	}
+}
+
+// Get returns the game state of player, starting a new game if nothing has
+// been saved for them yet.
+func (s *Sessions) Get(player string) (*GameState, error) {
+	return s.get(player, true)
+}
+
+// Find returns the game state of a player that has been saved before, or
+// ErrNotFound.
+func (s *Sessions) Find(player string) (*GameState, error) {
+	return s.get(player, false)
+}
+
+func (s *Sessions) get(player string, create bool) (*GameState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if gameState, exists := s.games[player]; exists {
+		return gameState, nil
+	}
+
+	gameState, err := loadGameState(s.content, s.store, player, create)
+	if err != nil {
+		return nil, err
+	}
+	gameState.Source = s.source
+
+	s.games[player] = gameState
+	return gameState, nil
+}
+
+// Len returns the number of cached players.
+func (s *Sessions) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.games)
+}
+
+// SaveAll saves the progress of every cached player.
+func (s *Sessions) SaveAll() error {
+	s.mu.Lock()
+	games := slices.Collect(maps.Values(s.games))
+	s.mu.Unlock()
+
+	var errs []error
+	for _, gameState := range games {
+		gameState.Lock()
+		if err := gameState.Save(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to save %s: %w", gameState.player, err))
+		}
+		gameState.Unlock()
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,290 @@
+package craft
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotFound = errors.New("no saved progress")
+	ErrCorrupt  = errors.New("saved progress is corrupt")
+	ErrRestored = errors.New("saved progress was damaged and has been restored from a backup")
+)
+
+// ProgressStore persists the progress of players keyed by player ID. Load
+// returns ErrNotFound for players that have never been saved. If a damaged
+// save was recovered, Load returns the recovered progress together with an
+// error wrapping ErrRestored.
+type ProgressStore interface {
+	Load(player string) (*Progress, error)
+	Save(player string, progress *Progress) error
+	Delete(player string) error
+	List() ([]string, error)
+}
+
+// CountTried adds up how often every combination, keyed by RecipeKey, was
+// tried by the players in store.
+func CountTried(store ProgressStore) (map[string]int, error) {
+	players, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, player := range players {
+		progress, err := store.Load(player)
+		if err != nil && !errors.Is(err, ErrRestored) {
+			return nil, fmt.Errorf("failed to load progress of %s: %w", player, err)
+		}
+		for key, attempt := range progress.Tried {
+			counts[key] += attempt.Count
+		}
+	}
+	return counts, nil
+}
+
+// DefaultBackups is the number of previous saves a new FileStore keeps next
+// to each save file.
+const DefaultBackups = 3
+
+// FileStore keeps one JSON file per player under Dir. Player IDs may contain
+// slashes to group players into subdirectories.
+//
+// Saves are written to a temporary file and renamed into place, keeping the
+// previous Backups versions as <player>.json.1, .2 and so on. If a save file
+// cannot be read, Load falls back to the newest valid backup, keeps the
+// damaged file as <player>.json.corrupt and saves the recovered progress in
+// its place.
+type FileStore struct {
+	Dir     string
+	Backups int
+}
+
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{Dir: dir, Backups: DefaultBackups}
+}
+
+// Path returns the file that holds the progress of player.
+func (s *FileStore) Path(player string) (string, error) {
+	path := filepath.Join(s.Dir, filepath.FromSlash(player)+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create progress directory: %w", err)
+	}
+	return path, nil
+}
+
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+func readProgressFile(path string) (*Progress, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := DecodeProgress(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return progress, nil
+}
+
+func (s *FileStore) Load(player string) (*Progress, error) {
+	path, err := s.Path(player)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, loadErr := readProgressFile(path)
+	if loadErr == nil {
+		return progress, nil
+	}
+
+	for i := 1; i <= s.Backups; i++ {
+		backup := backupPath(path, i)
+		progress, err := readProgressFile(backup)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Skipping unreadable backup: %v", err)
+			continue
+		}
+
+		if err := os.Rename(path, path+".corrupt"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if err := s.Save(player, progress); err != nil {
+			return nil, fmt.Errorf("failed to save restored progress: %w", err)
+		}
+		return progress, fmt.Errorf("%w (%s): %w", ErrRestored, filepath.Base(backup), loadErr)
+	}
+
+	if errors.Is(loadErr, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return nil, fmt.Errorf("%w: %w", ErrCorrupt, loadErr)
+}
+
+func (s *FileStore) Save(player string, progress *Progress) error {
+	path, err := s.Path(player)
+	if err != nil {
+		return err
+	}
+
+	data, err := EncodeProgress(progress)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	if err := s.rotateBackups(path); err != nil {
+		return fmt.Errorf("failed to rotate backups: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *FileStore) rotateBackups(path string) error {
+	if s.Backups <= 0 {
+		return nil
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	for i := s.Backups - 1; i >= 1; i-- {
+		err := os.Rename(backupPath(path, i), backupPath(path, i+1))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return os.Rename(path, backupPath(path, 1))
+}
+
+func (s *FileStore) Delete(player string) error {
+	path, err := s.Path(player)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i <= s.Backups; i++ {
+		target := path
+		if i > 0 {
+			target = backupPath(path, i)
+		}
+		if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) List() ([]string, error) {
+	var players []string
+
+	err := filepath.WalkDir(s.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.Dir, path)
+		if err != nil {
+			return err
+		}
+		players = append(players, strings.TrimSuffix(filepath.ToSlash(rel), ".json"))
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	return players, err
+}
+
+// MemoryStore keeps progress in memory only. It is mostly useful in tests.
+type MemoryStore struct {
+	mu       sync.Mutex
+	progress map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{progress: make(map[string][]byte)}
+}
+
+func (s *MemoryStore) Load(player string) (*Progress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, exists := s.progress[player]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return DecodeProgress(data)
+}
+
+func (s *MemoryStore) Save(player string, progress *Progress) error {
+	data, err := EncodeProgress(progress)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.progress[player] = data
+	return nil
+}
+
+func (s *MemoryStore) Delete(player string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.progress, player)
+	return nil
+}
+
+func (s *MemoryStore) List() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	players := make([]string, 0, len(s.progress))
+	for player := range s.progress {
+		players = append(players, player)
+	}
+	sort.Strings(players)
+	return players, nil
+}
@@ -0,0 +1,191 @@
+package craft
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func testStores(t *testing.T) map[string]ProgressStore {
+	t.Helper()
+
+	boltStore, err := OpenBoltStore(filepath.Join(t.TempDir(), "progress.db"))
+	if err != nil {
+		t.Fatalf("Failed to open bolt store: %v", err)
+	}
+	t.Cleanup(func() { boltStore.Close() })
+
+	return map[string]ProgressStore{
+		"file":   NewFileStore(t.TempDir()),
+		"bolt":   boltStore,
+		"memory": NewMemoryStore(),
+	}
+}
+
+func TestProgressStores(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.Load("telegram/42"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Load of unknown player: err = %v, want ErrNotFound", err)
+			}
+
+			if err := store.Save("progress", &Progress{Discovered: []string{"water", "fire"}}); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+			if err := store.Save("telegram/42", &Progress{Discovered: []string{"earth"}, Attempts: 3}); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+
+			progress, err := store.Load("telegram/42")
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if !slices.Equal(progress.Discovered, []string{"earth"}) || progress.Attempts != 3 {
+				t.Errorf("Load = %+v, want [earth] with 3 attempts", progress)
+			}
+
+			players, err := store.List()
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			slices.Sort(players)
+			if !slices.Equal(players, []string{"progress", "telegram/42"}) {
+				t.Errorf("List = %v, want [progress telegram/42]", players)
+			}
+
+			if err := store.Delete("telegram/42"); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if _, err := store.Load("telegram/42"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Load after Delete: err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestFileStoreLayout(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+
+	path, err := store.Path("telegram/42")
+	if err != nil {
+		t.Fatalf("Path failed: %v", err)
+	}
+	if want := filepath.Join(dir, "telegram", "42.json"); path != want {
+		t.Errorf("Path = %s, want %s", path, want)
+	}
+}
+
+func TestLoadGameStateReportsCorruptProgress(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	path, _ := store.Path("progress")
+	writeFile(t, path, "[\"water\", ")
+
+	if _, err := LoadGameState(testContent(t), store, "progress"); err == nil {
+		t.Fatal("LoadGameState succeeded on a corrupt save")
+	}
+}
+
+func TestLoadGameStateMigratesLegacySave(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	path, _ := store.Path("progress")
+	writeFile(t, path, `["water", "fire", "earth", "wind", "steam"]`)
+
+	gameState, err := LoadGameState(testContent(t), store, "progress")
+	if err != nil {
+		t.Fatalf("LoadGameState failed: %v", err)
+	}
+	if !gameState.IsDiscovered("steam") {
+		t.Errorf("Discovered = %v, want legacy elements", gameState.Discovered)
+	}
+}
+
+func TestFileStoreKeepsBackups(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+	store.Backups = 2
+
+	for i := 1; i <= 4; i++ {
+		progress := NewProgress()
+		progress.Attempts = i
+		if err := store.Save("progress", progress); err != nil {
+			t.Fatalf("Save %d failed: %v", i, err)
+		}
+	}
+
+	path, _ := store.Path("progress")
+	for n, want := range map[int]int{1: 3, 2: 2} {
+		backup, err := readProgressFile(backupPath(path, n))
+		if err != nil {
+			t.Fatalf("Backup %d: %v", n, err)
+		}
+		if backup.Attempts != want {
+			t.Errorf("Backup %d has %d attempts, want %d", n, backup.Attempts, want)
+		}
+	}
+
+	entries, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(entries) != 3 {
+		t.Errorf("Store directory contains %v, want the save and two backups", entries)
+	}
+}
+
+func TestFileStoreRecoversFromBackup(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+
+	for _, discovered := range [][]string{{"water"}, {"water", "steam"}} {
+		if err := store.Save("progress", &Progress{Discovered: discovered}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	path, _ := store.Path("progress")
+	writeFile(t, path, `{"version": 2, "discov`)
+
+	progress, err := store.Load("progress")
+	if !errors.Is(err, ErrRestored) {
+		t.Fatalf("Load: err = %v, want ErrRestored", err)
+	}
+	if !slices.Equal(progress.Discovered, []string{"water"}) {
+		t.Errorf("Recovered %v, want the newest backup", progress.Discovered)
+	}
+
+	if _, err := os.Stat(path + ".corrupt"); err != nil {
+		t.Errorf("Damaged save was not kept: %v", err)
+	}
+	if players, _ := store.List(); !slices.Equal(players, []string{"progress"}) {
+		t.Errorf("List = %v, want the restored player", players)
+	}
+	if progress, err := store.Load("progress"); err != nil || !slices.Equal(progress.Discovered, []string{"water"}) {
+		t.Errorf("Load after recovery = %v, %v, want the restored progress", progress, err)
+	}
+}
+
+func TestLoadGameStateReportsRestoredProgress(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	store.Save("progress", &Progress{Discovered: slices.Clone(StartingElements)})
+	store.Save("progress", &Progress{Discovered: slices.Clone(StartingElements)})
+
+	path, _ := store.Path("progress")
+	writeFile(t, path, "not json")
+
+	gameState, err := LoadGameState(testContent(t), store, "progress")
+	if err != nil {
+		t.Fatalf("LoadGameState failed: %v", err)
+	}
+	if !errors.Is(gameState.Restored, ErrRestored) {
+		t.Errorf("Restored = %v, want ErrRestored", gameState.Restored)
+	}
+}
+
+func TestFileStoreReportsCorruptionWithoutBackups(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	path, _ := store.Path("progress")
+	writeFile(t, path, "not json")
+
+	if _, err := store.Load("progress"); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Load: err = %v, want ErrCorrupt", err)
+	}
+}
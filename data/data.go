@@ -0,0 +1,6 @@
+package data
+
+import "embed"
+
+//go:embed *.json
+var FS embed.FS
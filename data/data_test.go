@@ -0,0 +1,144 @@
+package data
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tracepanic/open-craft/craft"
+)
+
+type TestElement struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+type Elements map[string]TestElement
+type Recipes map[string]craft.Recipe
+
+func TestDataValidation(t *testing.T) {
+	elementsFile, err := os.ReadFile("elements.json")
+	if err != nil {
+		t.Fatalf("Failed to read elements.json: %v", err)
+	}
+
+	var elements Elements
+	if err := json.Unmarshal(elementsFile, &elements); err != nil {
+		t.Fatalf("Failed to parse elements.json: %v", err)
+	}
+
+	recipesFile, err := os.ReadFile("recipes.json")
+	if err != nil {
+		t.Fatalf("Failed to read recipes.json: %v", err)
+	}
+
+	var recipes Recipes
+	if err := json.Unmarshal(recipesFile, &recipes); err != nil {
+		t.Fatalf("Failed to parse recipes.json: %v", err)
+	}
+
+	t.Run("Check for duplicate element indexes", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for index := range elements {
+			lowIndex := strings.ToLower(index)
+			if seen[lowIndex] {
+				t.Errorf("Duplicate element index (case-insensitive): %s", index)
+			}
+			seen[lowIndex] = true
+		}
+	})
+
+	t.Run("Check for duplicate element names", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for _, elem := range elements {
+			if seen[elem.Name] {
+				t.Errorf("Duplicate element name: %s", elem.Name)
+			}
+			seen[elem.Name] = true
+		}
+	})
+
+	t.Run("Check recipes", func(t *testing.T) {
+		seenCombos := make(map[string]bool)
+
+		for combo, recipe := range recipes {
+			inputs := craft.SplitRecipeKey(combo)
+			if len(inputs) < 2 {
+				t.Errorf("Invalid recipe format: %s", combo)
+				continue
+			}
+
+			for _, input := range inputs {
+				if _, exists := elements[input]; !exists {
+					t.Errorf("Recipe uses non-existent element: %s", input)
+				}
+			}
+
+			outputs := recipe.Outputs()
+			if len(outputs) == 0 {
+				t.Errorf("Recipe has no result: %s", combo)
+			}
+			for _, result := range outputs {
+				if _, exists := elements[result]; !exists {
+					t.Errorf("Recipe result is non-existent element: %s", result)
+				}
+			}
+
+			if recipe.Requires != nil {
+				for _, element := range recipe.Requires.Elements {
+					if _, exists := elements[element]; !exists {
+						t.Errorf("Recipe requires non-existent element: %s", element)
+					}
+				}
+			}
+
+			comboKey := craft.RecipeKey(inputs...)
+			if seenCombos[comboKey] {
+				t.Errorf("Duplicate recipe combination: %s", combo)
+			}
+			seenCombos[comboKey] = true
+		}
+	})
+}
+
+func TestReachability(t *testing.T) {
+	content, err := craft.LoadContent(FS)
+	if err != nil {
+		t.Fatalf("Failed to load content: %v", err)
+	}
+
+	analysis := content.Analyze(craft.StartingElements)
+
+	for _, element := range analysis.Unreachable {
+		t.Errorf("Element cannot be crafted from the starting elements: %s", element)
+	}
+
+	t.Logf("%d dead-end elements, %d recipe cycles", len(analysis.DeadEnds), len(analysis.Cycles))
+
+	for _, cycle := range analysis.Cycles {
+		loop := cycle.Loop
+		if len(loop) == 0 || len(loop) > len(cycle.Elements) || (len(loop) == 1) != (len(cycle.Elements) == 1) {
+			t.Errorf("Cycle through %v has a loop of %d recipes", cycle.Elements, len(loop))
+			continue
+		}
+		from := cycle.Elements[0]
+		for _, step := range loop {
+			if !slices.Contains(step.Inputs, from) {
+				t.Errorf("Loop %q does not follow on from %s", cycle, from)
+			}
+			from = step.Result
+		}
+		if from != cycle.Elements[0] {
+			t.Errorf("Loop %q does not lead back to %s", cycle, cycle.Elements[0])
+		}
+		t.Logf("Cycle through %v: %s", cycle.Elements, cycle)
+	}
+}
+
+func TestLint(t *testing.T) {
+	for _, issue := range craft.Lint(FS) {
+		t.Error(issue)
+	}
+}
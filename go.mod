@@ -2,4 +2,9 @@ module github.com/tracepanic/open-craft
 
 go 1.24.1
 
-require github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
+require (
+	github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
+	go.etcd.io/bbolt v1.4.0
+)
+
+require golang.org/x/sys v0.29.0 // indirect